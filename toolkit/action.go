@@ -0,0 +1,77 @@
+package toolkit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ActionMetadata is the contents of an action's metadata file, action.yml.
+type ActionMetadata struct {
+	Name        string                  `yaml:"name"`
+	Author      string                  `yaml:"author"`
+	Description string                  `yaml:"description"`
+	Inputs      map[string]ActionInput  `yaml:"inputs"`
+	Outputs     map[string]ActionOutput `yaml:"outputs"`
+}
+
+// ActionInput describes an input declared in an action's metadata file.
+type ActionInput struct {
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Default     string `yaml:"default"`
+}
+
+// ActionOutput describes an output declared in an action's metadata file.
+type ActionOutput struct {
+	Description string `yaml:"description"`
+}
+
+// LoadActionMetadata reads the action metadata file at path.
+func LoadActionMetadata(path string) (*ActionMetadata, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &ActionMetadata{}
+
+	if err := yaml.Unmarshal(contents, action); err != nil {
+		return nil, err
+	}
+
+	return action, nil
+}
+
+// ActionInputNames returns the sorted names of the inputs declared in the running action's
+// action.yml or action.yaml file, which is looked up in ActionPath.
+func (m *Metadata) ActionInputNames() ([]string, error) {
+	if len(m.ActionPath) == 0 {
+		return nil, fmt.Errorf("Action path not available, GITHUB_ACTION_PATH is not set")
+	}
+
+	path := filepath.Join(m.ActionPath, "action.yml")
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		path = filepath.Join(m.ActionPath, "action.yaml")
+	}
+
+	action, err := LoadActionMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(action.Inputs))
+
+	for name := range action.Inputs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
@@ -0,0 +1,75 @@
+package toolkit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const actionYAML = `
+name: Hello World
+description: Greets someone
+inputs:
+  who-to-greet:
+    description: Who to greet
+    required: true
+    default: World
+  greeting:
+    description: The greeting
+outputs:
+  time:
+    description: The time we greeted you
+`
+
+func Test_LoadActionMetadata(t *testing.T) {
+	dir := withActionFile(t, "action.yml", actionYAML)
+	action, err := LoadActionMetadata(filepath.Join(dir, "action.yml"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello World", action.Name)
+	assert.Equal(t, ActionInput{Description: "Who to greet", Required: true, Default: "World"}, action.Inputs["who-to-greet"])
+	assert.Equal(t, "The time we greeted you", action.Outputs["time"].Description)
+}
+
+func Test_ActionInputNames(t *testing.T) {
+	t.Run("action.yml", func(t *testing.T) {
+		meta := &Metadata{ActionPath: withActionFile(t, "action.yml", actionYAML)}
+		got, err := meta.ActionInputNames()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"greeting", "who-to-greet"}, got)
+	})
+
+	t.Run("action.yaml", func(t *testing.T) {
+		meta := &Metadata{ActionPath: withActionFile(t, "action.yaml", actionYAML)}
+		got, err := meta.ActionInputNames()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"greeting", "who-to-greet"}, got)
+	})
+
+	t.Run("Action path not available", func(t *testing.T) {
+		_, err := (&Metadata{}).ActionInputNames()
+
+		assert.EqualError(t, err, "Action path not available, GITHUB_ACTION_PATH is not set")
+	})
+}
+
+// withActionFile writes an action metadata file with the given name into a temporary directory and
+// returns the directory.
+func withActionFile(t *testing.T, name string, contents string) string {
+	dir, err := ioutil.TempDir("", "action-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
@@ -0,0 +1,103 @@
+package toolkit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+)
+
+// annotationJSON is the JSON representation of an Annotation.
+type annotationJSON struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	Title   string `json:"title,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	EndLine int    `json:"endLine,omitempty"`
+	Col     int    `json:"col,omitempty"`
+	EndCol  int    `json:"endColumn,omitempty"`
+}
+
+// MarshalJSON serialises the annotation, including its level and message, into JSON.
+func (a Annotation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(annotationJSON{
+		Level:   a.level,
+		Message: a.message,
+		Title:   a.Title,
+		File:    a.File,
+		Line:    a.Line,
+		EndLine: a.EndLine,
+		Col:     a.Col,
+		EndCol:  a.EndCol,
+	})
+}
+
+// UnmarshalJSON deserialises an annotation serialised by MarshalJSON.
+func (a *Annotation) UnmarshalJSON(data []byte) error {
+	var v annotationJSON
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*a = Annotation{
+		level:   v.Level,
+		message: v.Message,
+		Title:   v.Title,
+		File:    v.File,
+		Line:    v.Line,
+		EndLine: v.EndLine,
+		Col:     v.Col,
+		EndCol:  v.EndCol,
+	}
+
+	return nil
+}
+
+// AppendAnnotationsToFile appends annotations to the file at path, one JSON object per line. This
+// allows multiple steps to collect their annotations in a single file for later processing.
+func AppendAnnotationsToFile(path string, annotations []Annotation) error {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+
+	for _, annotation := range annotations {
+		if err := encoder.Encode(annotation); err != nil {
+			return err
+		}
+	}
+
+	_, err := appendToFile(path, buffer.Bytes())
+
+	return err
+}
+
+// LoadAnnotationsFromFile reads all annotations from a file written by AppendAnnotationsToFile.
+func LoadAnnotationsFromFile(path string) ([]Annotation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	annotations := make([]Annotation, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var annotation Annotation
+
+		if err := json.Unmarshal(scanner.Bytes(), &annotation); err != nil {
+			return nil, err
+		}
+
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations, scanner.Err()
+}
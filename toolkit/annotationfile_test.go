@@ -0,0 +1,50 @@
+package toolkit
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_AnnotationJSON(t *testing.T) {
+	a := NewWarning("hello world").SetPosition("main.go", 5, 4)
+	data, err := json.Marshal(a)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"level": "warning", "message": "hello world", "file": "main.go", "line": 5, "col": 4}`, string(data))
+
+	var got Annotation
+
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, a, got)
+}
+
+func Test_AnnotationsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "annotations-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "annotations.json")
+	first := []Annotation{NewError("first").SetPosition("main.go", 1, 2)}
+	second := []Annotation{NewWarning("second\nline"), NewNotice("third")}
+
+	assert.NoError(t, AppendAnnotationsToFile(path, first))
+	assert.NoError(t, AppendAnnotationsToFile(path, second))
+
+	got, err := LoadAnnotationsFromFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, append(first, second...), got)
+}
+
+func Test_LoadAnnotationsFromFile(t *testing.T) {
+	_, err := LoadAnnotationsFromFile("/nonexistent/annotations.json")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+// Package assert implements test helpers for code which produces toolkit annotations.
+package assert
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/robertrossmann/actions/toolkit"
+)
+
+// AssertAnnotation fails the test if want is not one of the annotations in got.
+func AssertAnnotation(t testing.TB, got []toolkit.Annotation, want toolkit.Annotation) {
+	t.Helper()
+
+	for _, a := range got {
+		if a == want {
+			return
+		}
+	}
+
+	t.Errorf("expected annotation %s, got: %s", want, format(got))
+}
+
+// AssertAnnotationCount fails the test unless got contains exactly count annotations of the given
+// level.
+func AssertAnnotationCount(t testing.TB, got []toolkit.Annotation, level toolkit.AnnotationLevel, count int) {
+	t.Helper()
+
+	matching := filter(got, level)
+
+	if len(matching) != count {
+		t.Errorf("expected %d %s annotations, got %d: %s", count, level, len(matching), format(matching))
+	}
+}
+
+// AssertNoErrors fails the test if got contains any error-level annotations.
+func AssertNoErrors(t testing.TB, got []toolkit.Annotation) {
+	t.Helper()
+	AssertAnnotationCount(t, got, toolkit.LevelError, 0)
+}
+
+func filter(annotations []toolkit.Annotation, level toolkit.AnnotationLevel) []toolkit.Annotation {
+	matching := make([]toolkit.Annotation, 0)
+
+	for _, a := range annotations {
+		if a.Severity() == int(level) {
+			matching = append(matching, a)
+		}
+	}
+
+	return matching
+}
+
+func format(annotations []toolkit.Annotation) string {
+	lines := make([]string, len(annotations))
+
+	for i, a := range annotations {
+		lines[i] = a.String()
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(lines, ", "))
+}
@@ -0,0 +1,82 @@
+package assert
+
+import (
+	"fmt"
+	"github.com/robertrossmann/actions/toolkit"
+	"testing"
+)
+
+var annotations = []toolkit.Annotation{
+	toolkit.NewError("first"),
+	toolkit.NewWarning("second"),
+	toolkit.NewError("third"),
+}
+
+func Test_AssertAnnotation(t *testing.T) {
+	t.Run("Present", func(t *testing.T) {
+		tb := &fakeTB{}
+		AssertAnnotation(tb, annotations, toolkit.NewWarning("second"))
+
+		expectErrors(t, tb)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		tb := &fakeTB{}
+		AssertAnnotation(tb, annotations, toolkit.NewWarning("first"))
+
+		expectErrors(t, tb, "expected annotation ::warning::first, got: [::error::first, ::warning::second, ::error::third]")
+	})
+}
+
+func Test_AssertAnnotationCount(t *testing.T) {
+	t.Run("Matching count", func(t *testing.T) {
+		tb := &fakeTB{}
+		AssertAnnotationCount(tb, annotations, toolkit.LevelError, 2)
+
+		expectErrors(t, tb)
+	})
+
+	t.Run("Different count", func(t *testing.T) {
+		tb := &fakeTB{}
+		AssertAnnotationCount(tb, annotations, toolkit.LevelError, 1)
+
+		expectErrors(t, tb, "expected 1 error annotations, got 2: [::error::first, ::error::third]")
+	})
+}
+
+func Test_AssertNoErrors(t *testing.T) {
+	t.Run("No errors", func(t *testing.T) {
+		tb := &fakeTB{}
+		AssertNoErrors(tb, []toolkit.Annotation{toolkit.NewWarning("second")})
+
+		expectErrors(t, tb)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tb := &fakeTB{}
+		AssertNoErrors(tb, annotations)
+
+		expectErrors(t, tb, "expected 0 error annotations, got 2: [::error::first, ::error::third]")
+	})
+}
+
+// expectErrors fails the test unless tb recorded exactly the given failure messages.
+func expectErrors(t *testing.T, tb *fakeTB, want ...string) {
+	t.Helper()
+
+	if fmt.Sprint(tb.errors) != fmt.Sprint(want) {
+		t.Errorf("expected failures %q, got %q", want, tb.errors)
+	}
+}
+
+// fakeTB records failures without failing the real test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,167 @@
+package toolkit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Batch accumulates outputs, environment variables and path entries in memory and writes them all at
+// once when committed. Each of the runner's files is written exactly once, which avoids the I/O
+// overhead of configuring many values one by one. Runners which do not provide the files receive the
+// equivalent workflow commands in a single write instead.
+type Batch struct {
+	outputs [][2]string
+	env     [][2]string
+	paths   []string
+}
+
+// NewBatch creates a new, empty Batch.
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// SetOutput adds an action's output parameter to the batch.
+func (b *Batch) SetOutput(name string, value string) *Batch {
+	b.outputs = append(b.outputs, [2]string{name, value})
+	return b
+}
+
+// Setenv adds an environment variable for subsequent actions in the job to the batch.
+func (b *Batch) Setenv(key string, value string) *Batch {
+	b.env = append(b.env, [2]string{key, value})
+	return b
+}
+
+// PrependPath adds a directory which should be prepended to the system PATH to the batch.
+func (b *Batch) PrependPath(path string) *Batch {
+	b.paths = append(b.paths, path)
+	return b
+}
+
+// Commit applies all operations in the batch and empties it. The environment variables and PATH of
+// the current process are updated as well, just like Setenv and PrependPath do. Outputs share the
+// duplicate tracking of SetOutput, so an output set more than once, in any way, writes a warning.
+// Nothing is written if any of the outputs or environment variable names is invalid, or if any of
+// the paths is relative, in which case ErrRelativePath is returned just like PrependPath does.
+func (b *Batch) Commit() error {
+	for _, output := range b.outputs {
+		if err := validateOutput(output[0], output[1]); err != nil {
+			return err
+		}
+	}
+
+	for _, env := range b.env {
+		if !envNamePattern.MatchString(env[0]) {
+			return ErrInvalidEnvName
+		}
+	}
+
+	for _, path := range b.paths {
+		if !filepath.IsAbs(path) {
+			return ErrRelativePath
+		}
+	}
+
+	for _, output := range b.outputs {
+		if !markOutput(output[0]) {
+			if _, err := Warning(fmt.Sprintf("output %s was set more than once", output[0])); err != nil {
+				return err
+			}
+		}
+	}
+
+	files := make(map[string]*bytes.Buffer)
+	commands := &bytes.Buffer{}
+	envCommands := commands
+
+	if writer(&envOut) != nil {
+		envCommands = &bytes.Buffer{}
+	}
+
+	for _, output := range b.outputs {
+		queue(files, "GITHUB_OUTPUT", fileCommand(output[0], output[1]),
+			commands, fmt.Sprintf("::set-output name=%s::%s\n", output[0], output[1]))
+	}
+
+	for _, env := range b.env {
+		queue(files, "GITHUB_ENV", fileCommand(env[0], env[1]),
+			envCommands, fmt.Sprintf("::set-env name=%s::%s\n", env[0], env[1]))
+
+		if err := os.Setenv(env[0], env[1]); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range b.paths {
+		queue(files, "GITHUB_PATH", path+"\n", commands, fmt.Sprintf("::add-path::%s\n", path))
+
+		parts := []string{path, os.Getenv("PATH")}
+
+		if err := os.Setenv("PATH", strings.Join(parts, string(os.PathListSeparator))); err != nil {
+			return err
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+
+	for path := range files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if _, err := appendToFile(path, files[path].Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if commands.Len() != 0 {
+		if _, err := writer(&out).Write(commands.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if envCommands != commands && envCommands.Len() != 0 {
+		if _, err := writer(&envOut).Write(envCommands.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	*b = Batch{}
+
+	return nil
+}
+
+// queue appends content to the buffer of the file referenced by the envName environment variable.
+// If the runner does not provide the file, command is appended to commands instead.
+func queue(files map[string]*bytes.Buffer, envName, content string, commands *bytes.Buffer, command string) {
+	path := os.Getenv(envName)
+
+	if len(path) == 0 {
+		commands.WriteString(command)
+		return
+	}
+
+	if files[path] == nil {
+		files[path] = &bytes.Buffer{}
+	}
+
+	files[path].WriteString(content)
+}
+
+// fileCommand formats a key-value pair for the runner's GITHUB_OUTPUT and GITHUB_ENV files. A
+// heredoc-style delimiter is used so that values may span multiple lines.
+func fileCommand(key string, value string) string {
+	delimiter := "ghadelimiter"
+
+	for strings.Contains(value, delimiter) {
+		delimiter += "_"
+	}
+
+	return fmt.Sprintf("%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+}
@@ -0,0 +1,72 @@
+package toolkit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func Test_Batch(t *testing.T) {
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	t.Run("Workflow commands", func(t *testing.T) {
+		defer ResetOutputs()
+
+		for _, key := range []string{"GITHUB_OUTPUT", "GITHUB_ENV", "GITHUB_PATH"} {
+			restoreEnv(t, key)
+			os.Unsetenv(key)
+		}
+
+		want := "::set-output name=first::one\n::set-env name=TEST_ENV_VAR::testvalue\n::add-path::/usr/dummy/bin\n"
+		got := capture(func() {
+			NewBatch().
+				SetOutput("first", "one").
+				Setenv("TEST_ENV_VAR", "testvalue").
+				PrependPath("/usr/dummy/bin").
+				Commit()
+		})
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Duplicate output", func(t *testing.T) {
+		defer ResetOutputs()
+
+		for _, key := range []string{"GITHUB_OUTPUT", "GITHUB_ENV", "GITHUB_PATH"} {
+			restoreEnv(t, key)
+			os.Unsetenv(key)
+		}
+
+		want := "::set-output name=first::one\n" +
+			"::warning::output first was set more than once\n" +
+			"::set-output name=first::two\n"
+		got := capture(func() {
+			SetOutput("first", "one")
+			NewBatch().SetOutput("first", "two").Commit()
+		})
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Empties the batch", func(t *testing.T) {
+		defer ResetOutputs()
+		for _, key := range []string{"GITHUB_OUTPUT", "GITHUB_ENV", "GITHUB_PATH"} {
+			restoreEnv(t, key)
+			os.Unsetenv(key)
+		}
+
+		batch := NewBatch().SetOutput("first", "one")
+		got := capture(func() {
+			batch.Commit()
+			batch.Commit()
+		})
+
+		assert.Equal(t, "::set-output name=first::one\n", got)
+	})
+}
+
+func Test_fileCommand(t *testing.T) {
+	assert.Equal(t, "key<<ghadelimiter_\nghadelimiter\nghadelimiter_\n", fileCommand("key", "ghadelimiter"))
+}
@@ -0,0 +1,32 @@
+package toolkit
+
+import "os"
+
+// Capabilities describes which GitHub Actions features are supported by the current runner.
+type Capabilities struct {
+	// FileBasedOutputs is true if outputs can be set via the GITHUB_OUTPUT file.
+	FileBasedOutputs bool
+	// FileBasedEnv is true if environment variables can be set via the GITHUB_ENV file.
+	FileBasedEnv bool
+	// FileBasedPath is true if the system path can be updated via the GITHUB_PATH file.
+	FileBasedPath bool
+	// StepSummary is true if a step summary can be written via the GITHUB_STEP_SUMMARY file.
+	StepSummary bool
+	// OIDCToken is true if the job is permitted to request an OpenID Connect token.
+	OIDCToken bool
+	// ProblemMatchers is true if problem matchers can be registered, ie. when running inside
+	// GitHub Actions.
+	ProblemMatchers bool
+}
+
+// DetectCapabilities inspects the environment to find out which features the runner supports.
+func DetectCapabilities() Capabilities {
+	return Capabilities{
+		FileBasedOutputs: len(os.Getenv("GITHUB_OUTPUT")) != 0,
+		FileBasedEnv:     len(os.Getenv("GITHUB_ENV")) != 0,
+		FileBasedPath:    len(os.Getenv("GITHUB_PATH")) != 0,
+		StepSummary:      len(os.Getenv("GITHUB_STEP_SUMMARY")) != 0,
+		OIDCToken:        len(os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")) != 0,
+		ProblemMatchers:  os.Getenv("GITHUB_ACTIONS") == "true",
+	}
+}
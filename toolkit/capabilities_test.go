@@ -0,0 +1,35 @@
+package toolkit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func Test_DetectCapabilities(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_OUTPUT":                "/tmp/output",
+		"GITHUB_ENV":                   "",
+		"GITHUB_PATH":                  "/tmp/path",
+		"GITHUB_STEP_SUMMARY":          "",
+		"ACTIONS_ID_TOKEN_REQUEST_URL": "https://token.actions.githubusercontent.com",
+		"GITHUB_ACTIONS":               "true",
+	}
+
+	for key, value := range env {
+		original := os.Getenv(key)
+		os.Setenv(key, value)
+		defer os.Setenv(key, original)
+	}
+
+	want := Capabilities{
+		FileBasedOutputs: true,
+		FileBasedEnv:     false,
+		FileBasedPath:    true,
+		StepSummary:      false,
+		OIDCToken:        true,
+		ProblemMatchers:  true,
+	}
+
+	assert.Equal(t, want, DetectCapabilities())
+}
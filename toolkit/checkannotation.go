@@ -0,0 +1,46 @@
+package toolkit
+
+// CheckAnnotation is an annotation in the format accepted by the GitHub Checks API when creating or
+// updating a check run.
+type CheckAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	StartColumn     int    `json:"start_column,omitempty"`
+	EndColumn       int    `json:"end_column,omitempty"`
+	AnnotationLevel string `json:"annotation_level"`
+	Message         string `json:"message"`
+	Title           string `json:"title,omitempty"`
+	RawDetails      string `json:"raw_details,omitempty"`
+}
+
+// checkAnnotationLevels maps annotation levels to the levels supported by the Checks API.
+var checkAnnotationLevels = map[string]string{
+	"debug":   "notice",
+	"notice":  "notice",
+	"warning": "warning",
+	"error":   "failure",
+}
+
+// ToGitHubCheckAnnotation converts the annotation into the Checks API format. Debug annotations
+// become notices and errors become failures. The end line defaults to the start line since the API
+// requires both.
+func (a Annotation) ToGitHubCheckAnnotation() CheckAnnotation {
+	a = a.normalise()
+	endLine := a.EndLine
+
+	if endLine == 0 {
+		endLine = a.Line
+	}
+
+	return CheckAnnotation{
+		Path:            a.File,
+		StartLine:       a.Line,
+		EndLine:         endLine,
+		StartColumn:     a.Col,
+		EndColumn:       a.EndCol,
+		AnnotationLevel: checkAnnotationLevels[a.level],
+		Message:         a.message,
+		Title:           a.Title,
+	}
+}
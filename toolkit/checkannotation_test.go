@@ -0,0 +1,40 @@
+package toolkit
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ToGitHubCheckAnnotation(t *testing.T) {
+	t.Run("Levels", func(t *testing.T) {
+		assert.Equal(t, "notice", NewDebug("message").ToGitHubCheckAnnotation().AnnotationLevel)
+		assert.Equal(t, "notice", NewNotice("message").ToGitHubCheckAnnotation().AnnotationLevel)
+		assert.Equal(t, "warning", NewWarning("message").ToGitHubCheckAnnotation().AnnotationLevel)
+		assert.Equal(t, "failure", NewError("message").ToGitHubCheckAnnotation().AnnotationLevel)
+	})
+
+	t.Run("Position", func(t *testing.T) {
+		annotation := NewAnnotation(LevelError, "unused variable", WithTitle("lint"), WithFile("main.go"), WithLine(3), WithCol(5))
+
+		want := CheckAnnotation{
+			Path:            "main.go",
+			StartLine:       3,
+			EndLine:         3,
+			StartColumn:     5,
+			AnnotationLevel: "failure",
+			Message:         "unused variable",
+			Title:           "lint",
+		}
+
+		assert.Equal(t, want, annotation.ToGitHubCheckAnnotation())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		annotation := NewWarning("deprecated").SetLineRange("main.go", 1, 4, 0, 0)
+		got, err := json.Marshal(annotation.ToGitHubCheckAnnotation())
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"path":"main.go","start_line":1,"end_line":4,"annotation_level":"warning","message":"deprecated"}`, string(got))
+	})
+}
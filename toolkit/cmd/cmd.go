@@ -0,0 +1,78 @@
+// Package cmd implements a standard entrypoint for actions written in Go. It takes care of the
+// setup every action's main package needs, so that an action can be reduced to:
+//
+//	func main() {
+//		os.Exit(cmd.Run(func(log *toolkit.Logger) int {
+//			log.Debug("hello world")
+//			return 0
+//		}))
+//	}
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/robertrossmann/actions/toolkit"
+)
+
+// Run calls mainFunc with a Logger writing to the toolkit's output writer, which is the standard
+// output unless changed with toolkit.SetOutputWriter, and returns its exit code.
+//
+// Before mainFunc is called, a warning is logged when not running inside GitHub Actions, or when
+// the runner did not provide the metadata every action relies on. Debug messages are only logged if
+// debug logging is enabled for the run, ie. RUNNER_DEBUG is 1 or ACTIONS_STEP_DEBUG is true.
+// If mainFunc panics, the panic is logged as an error annotation with the stack trace as a debug
+// message and an exit code of 1 is returned.
+func Run(mainFunc func(*toolkit.Logger) int) (code int) {
+	log := toolkit.NewLogger(nil)
+
+	if !isDebug() {
+		log.SetMinLevel(toolkit.LevelNotice)
+	}
+
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		log.Warning("Not running inside GitHub Actions, some features may not be available")
+	} else if missing := missingMetadata(toolkit.GetMetadata()); len(missing) != 0 {
+		log.Warning(fmt.Sprintf("Metadata not available: %s", strings.Join(missing, ", ")))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error(fmt.Sprintf("Action panicked: %v", r))
+			log.Debug(string(debug.Stack()))
+			code = 1
+		}
+	}()
+
+	return mainFunc(log)
+}
+
+// isDebug reports whether debug logging is enabled for the current run.
+func isDebug() bool {
+	return os.Getenv("RUNNER_DEBUG") == "1" || os.Getenv("ACTIONS_STEP_DEBUG") == "true"
+}
+
+// missingMetadata returns the environment variables of the essential metadata which are not set.
+func missingMetadata(meta *toolkit.Metadata) []string {
+	missing := make([]string, 0)
+	required := []struct {
+		value string
+		key   string
+	}{
+		{meta.EventName, "GITHUB_EVENT_NAME"},
+		{meta.Repository, "GITHUB_REPOSITORY"},
+		{meta.Sha, "GITHUB_SHA"},
+		{meta.Workspace, "GITHUB_WORKSPACE"},
+	}
+
+	for _, field := range required {
+		if len(field.value) == 0 {
+			missing = append(missing, field.key)
+		}
+	}
+
+	return missing
+}
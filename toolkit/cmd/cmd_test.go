@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/robertrossmann/actions/toolkit"
+	"github.com/robertrossmann/actions/toolkit/testenv"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_Run(t *testing.T) {
+	t.Run("Exit code", func(t *testing.T) {
+		withRunner(t)
+
+		var code int
+		got := capture(func() {
+			code = Run(func(log *toolkit.Logger) int {
+				log.Notice("hello world")
+				return 3
+			})
+		})
+
+		assert.Equal(t, 3, code)
+		assert.Equal(t, "::notice::hello world\n", got)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		withRunner(t)
+		testenv.Setenv(t, "RUNNER_DEBUG", "1")
+
+		var code int
+		got := capture(func() {
+			code = Run(func(log *toolkit.Logger) int {
+				panic("boom")
+			})
+		})
+
+		assert.Equal(t, 1, code)
+		assert.True(t, strings.HasPrefix(got, "::error::Action panicked: boom\n::debug::goroutine "))
+	})
+
+	t.Run("Outside GitHub Actions", func(t *testing.T) {
+		withRunner(t)
+		testenv.Setenv(t, "GITHUB_ACTIONS", "")
+
+		got := capture(func() {
+			Run(func(log *toolkit.Logger) int {
+				return 0
+			})
+		})
+
+		assert.Equal(t, "::warning::Not running inside GitHub Actions, some features may not be available\n", got)
+	})
+
+	t.Run("Missing metadata", func(t *testing.T) {
+		withRunner(t)
+		testenv.Setenv(t, "GITHUB_SHA", "")
+		testenv.Setenv(t, "GITHUB_WORKSPACE", "")
+
+		got := capture(func() {
+			Run(func(log *toolkit.Logger) int {
+				return 0
+			})
+		})
+
+		assert.Equal(t, "::warning::Metadata not available: GITHUB_SHA, GITHUB_WORKSPACE\n", got)
+	})
+}
+
+func Test_Run_DebugMode(t *testing.T) {
+	debug := func() string {
+		return capture(func() {
+			Run(func(log *toolkit.Logger) int {
+				log.Debug("hello world")
+				return 0
+			})
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		withRunner(t)
+
+		assert.Empty(t, debug())
+	})
+
+	t.Run("RUNNER_DEBUG", func(t *testing.T) {
+		withRunner(t)
+		testenv.Setenv(t, "RUNNER_DEBUG", "1")
+
+		assert.Equal(t, "::debug::hello world\n", debug())
+	})
+
+	t.Run("ACTIONS_STEP_DEBUG", func(t *testing.T) {
+		withRunner(t)
+		testenv.Setenv(t, "ACTIONS_STEP_DEBUG", "true")
+
+		assert.Equal(t, "::debug::hello world\n", debug())
+	})
+}
+
+// withRunner sets the environment of a GitHub Actions run with complete metadata and debug logging
+// disabled for the duration of the test.
+func withRunner(t *testing.T) {
+	testenv.Setenv(t, "GITHUB_ACTIONS", "true")
+	testenv.Setenv(t, "GITHUB_EVENT_NAME", "push")
+	testenv.Setenv(t, "GITHUB_REPOSITORY", "octocat/hello-world")
+	testenv.Setenv(t, "GITHUB_SHA", "6dcb09b5b57875f334f61aebed695e2e4193db5e")
+	testenv.Setenv(t, "GITHUB_WORKSPACE", "/home/runner/work/hello-world")
+	testenv.Setenv(t, "GITHUB_TOKEN", "")
+	testenv.Setenv(t, "RUNNER_DEBUG", "")
+	testenv.Setenv(t, "ACTIONS_STEP_DEBUG", "")
+}
+
+// capture redirects the toolkit's output writer to a buffer while f runs and returns the output.
+func capture(f func()) string {
+	buffer := &bytes.Buffer{}
+	toolkit.SetOutputWriter(buffer)
+	f()
+	toolkit.SetOutputWriter(nil)
+
+	return buffer.String()
+}
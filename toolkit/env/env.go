@@ -0,0 +1,63 @@
+// Package env implements typed accessors for environment variables. Values which are not set, empty
+// or cannot be parsed fall back to the supplied default value.
+package env
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// MustString returns the value of the environment variable key. It panics if the variable is not set
+// or empty.
+func MustString(key string) string {
+	value := os.Getenv(key)
+
+	if len(value) == 0 {
+		panic(fmt.Sprintf("Environment variable %s not set or empty string", key))
+	}
+
+	return value
+}
+
+// String returns the value of the environment variable key, or defaultValue if it is not set or
+// empty.
+func String(key, defaultValue string) string {
+	if value := os.Getenv(key); len(value) != 0 {
+		return value
+	}
+
+	return defaultValue
+}
+
+// Int returns the value of the environment variable key parsed as an integer.
+func Int(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// Bool returns the value of the environment variable key parsed as a boolean. All values accepted by
+// strconv.ParseBool are supported.
+func Bool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// Duration returns the value of the environment variable key parsed as a duration, ie. 1m30s.
+func Duration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
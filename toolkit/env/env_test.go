@@ -0,0 +1,68 @@
+package env
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_MustString(t *testing.T) {
+	t.Run("Set", func(t *testing.T) {
+		os.Setenv("TEST_ENV_VAR", "testvalue")
+		defer os.Unsetenv("TEST_ENV_VAR")
+
+		assert.Equal(t, "testvalue", MustString("TEST_ENV_VAR"))
+	})
+
+	t.Run("Not set", func(t *testing.T) {
+		assert.PanicsWithValue(t, "Environment variable TEST_ENV_VAR not set or empty string", func() {
+			MustString("TEST_ENV_VAR")
+		})
+	})
+}
+
+func Test_String(t *testing.T) {
+	assert.Equal(t, "default", String("TEST_ENV_VAR", "default"))
+
+	os.Setenv("TEST_ENV_VAR", "testvalue")
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	assert.Equal(t, "testvalue", String("TEST_ENV_VAR", "default"))
+}
+
+func Test_Int(t *testing.T) {
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	assert.Equal(t, 5, Int("TEST_ENV_VAR", 5))
+
+	os.Setenv("TEST_ENV_VAR", "42")
+	assert.Equal(t, 42, Int("TEST_ENV_VAR", 5))
+
+	os.Setenv("TEST_ENV_VAR", "forty-two")
+	assert.Equal(t, 5, Int("TEST_ENV_VAR", 5))
+}
+
+func Test_Bool(t *testing.T) {
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	assert.Equal(t, true, Bool("TEST_ENV_VAR", true))
+
+	os.Setenv("TEST_ENV_VAR", "false")
+	assert.Equal(t, false, Bool("TEST_ENV_VAR", true))
+
+	os.Setenv("TEST_ENV_VAR", "nope")
+	assert.Equal(t, true, Bool("TEST_ENV_VAR", true))
+}
+
+func Test_Duration(t *testing.T) {
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	assert.Equal(t, time.Second, Duration("TEST_ENV_VAR", time.Second))
+
+	os.Setenv("TEST_ENV_VAR", "1m30s")
+	assert.Equal(t, 90*time.Second, Duration("TEST_ENV_VAR", time.Second))
+
+	os.Setenv("TEST_ENV_VAR", "soon")
+	assert.Equal(t, time.Second, Duration("TEST_ENV_VAR", time.Second))
+}
@@ -0,0 +1,258 @@
+package toolkit
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+)
+
+// ExitFunc terminates the process after a fatal error. It can be replaced, eg. in tests, to observe
+// the exit code without exiting.
+var ExitFunc = os.Exit
+
+// ExitCode is the code an action exits with.
+type ExitCode int
+
+// Exit codes understood by the runner.
+const (
+	ExitSuccess ExitCode = 0
+	ExitFailure ExitCode = 1
+)
+
+// exitCode is the code recorded with SetExitCode.
+var exitCode int32
+
+// SetExitCode records the code the action should exit with once it finishes, allowing it to carry on
+// after an error and report further problems. It is safe to call concurrently.
+func SetExitCode(code ExitCode) {
+	atomic.StoreInt32(&exitCode, int32(code))
+}
+
+// ExitCodeOrZero returns the code recorded with SetExitCode, or 0 if none was recorded. Since
+// deferred arguments are evaluated immediately, it must be called inside the deferred function:
+//
+//	defer func() { os.Exit(toolkit.ExitCodeOrZero()) }()
+func ExitCodeOrZero() int {
+	return int(atomic.LoadInt32(&exitCode))
+}
+
+// MultiAnnotateError is an error made up of multiple annotations. It allows an action to collect
+// all problems it encounters and report them at once before failing.
+type MultiAnnotateError []Annotation
+
+// Error summarises the number of annotations by level.
+func (e MultiAnnotateError) Error() string {
+	counts := make(map[string]int)
+
+	for _, annotation := range e {
+		counts[annotation.level]++
+	}
+
+	parts := make([]string, 0)
+
+	for _, level := range []string{"error", "warning", "notice", "debug"} {
+		if counts[level] != 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[level], level))
+		}
+	}
+
+	return fmt.Sprintf("%d annotations (%s)", len(e), strings.Join(parts, ", "))
+}
+
+// Emit writes all annotations to w.
+func (e MultiAnnotateError) Emit(w io.Writer) error {
+	for _, annotation := range e {
+		if _, err := fmt.Fprintln(w, annotation.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EmitAndFail reports err and returns the exit code the action should exit with. A nil error
+// results in an exit code of 0. If err is or wraps a MultiAnnotateError, all of its annotations are
+// written, otherwise err is written as an error-level message. In both cases 1 is returned.
+func EmitAndFail(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var multi MultiAnnotateError
+
+	if errors.As(err, &multi) {
+		for _, annotation := range multi {
+			Annotate(annotation)
+		}
+	} else {
+		Error(err.Error())
+	}
+
+	return 1
+}
+
+// AnnotationError is an error carrying the annotation it should be reported as. This allows rich
+// annotation data, such as the file and line of a problem, to be propagated up the call stack.
+type AnnotationError struct {
+	Annotation
+	cause error
+}
+
+// NewAnnotationError creates an AnnotationError which reports cause as annotation. The cause may be
+// nil, in which case the annotation alone describes the error.
+func NewAnnotationError(annotation Annotation, cause error) *AnnotationError {
+	return &AnnotationError{Annotation: annotation, cause: cause}
+}
+
+// Error returns the message of the underlying error, or the annotation's message if there is none.
+func (e *AnnotationError) Error() string {
+	if e.cause == nil {
+		return e.message
+	}
+
+	return e.cause.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *AnnotationError) Unwrap() error {
+	return e.cause
+}
+
+// Emit writes the error's annotation to the action output.
+func (e *AnnotationError) Emit() error {
+	_, err := Annotate(e.Annotation)
+	return err
+}
+
+// EmitErrors walks the chain of errors wrapped by err and emits every AnnotationError found in it.
+func EmitErrors(err error) error {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if annotated, ok := err.(*AnnotationError); ok {
+			if emitErr := annotated.Emit(); emitErr != nil {
+				return emitErr
+			}
+		}
+	}
+
+	return nil
+}
+
+// Wrapf wraps err with a message formatted according to a format specifier, writes the result as an
+// error annotation and returns it. The returned error wraps err, so errors.Is and errors.As keep
+// working. A nil error is returned unchanged and nothing is written.
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	wrapped := fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+	Error(wrapped.Error())
+
+	return wrapped
+}
+
+// Recover converts a panic into an error annotation and exits the process with code 1. The stack
+// trace is written as a debug message so that it does not clutter the pull request. It must be
+// deferred directly, ie. defer toolkit.Recover() at the start of main.
+func Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	Error(fmt.Sprintf("panic: %v", r))
+	Debug(string(debug.Stack()))
+	ExitFunc(1)
+}
+
+// Fatal writes message as an error annotation and exits the process with code 1.
+func Fatal(message string) {
+	Error(message)
+	ExitFunc(1)
+}
+
+// Fatalf is like Fatal but formats the message according to a format specifier.
+func Fatalf(format string, args ...interface{}) {
+	Fatal(fmt.Sprintf(format, args...))
+}
+
+// PositionedError is implemented by errors which know the file and line they refer to.
+type PositionedError interface {
+	error
+	File() string
+	Line() int
+}
+
+// NewAnnotationFromError creates an annotation of the given level with err's message. If err is or
+// wraps a PositionedError, the annotation is positioned at its file and line, otherwise err is
+// converted with NewAnnotationFromGoError.
+func NewAnnotationFromError(level AnnotationLevel, err error) Annotation {
+	var positioned PositionedError
+	if errors.As(err, &positioned) {
+		return NewAnnotation(level, err.Error(), WithFile(positioned.File()), WithLine(positioned.Line()))
+	}
+
+	return NewAnnotationFromGoError(level, err)
+}
+
+// NewAnnotationFromGoError creates an annotation of the given level from an error produced by Go
+// tooling. If err implements a Pos() token.Position method, or is a go/scanner error as returned by
+// the go/parser package, the annotation is positioned accordingly. Only the first entry of a
+// scanner.ErrorList is positioned and the number of the remaining ones is added to its message, the
+// same way the list's Error method does; use NewAnnotationsFromGoError to report all of them.
+func NewAnnotationFromGoError(level AnnotationLevel, err error) Annotation {
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) != 0 {
+		message := list[0].Msg
+
+		if len(list) > 1 {
+			message = fmt.Sprintf("%s (and %d more errors)", message, len(list)-1)
+		}
+
+		return NewAnnotation(level, message, withPosition(list[0].Pos))
+	}
+
+	var scanErr *scanner.Error
+	if errors.As(err, &scanErr) {
+		return NewAnnotation(level, scanErr.Msg, withPosition(scanErr.Pos))
+	}
+
+	var positioned interface{ Pos() token.Position }
+	if errors.As(err, &positioned) {
+		return NewAnnotation(level, err.Error(), withPosition(positioned.Pos()))
+	}
+
+	return NewAnnotation(level, err.Error())
+}
+
+// NewAnnotationsFromGoError works like NewAnnotationFromGoError, but returns one annotation per entry
+// if err is a scanner.ErrorList, so that every problem reported by the go/parser package is
+// annotated at its own position.
+func NewAnnotationsFromGoError(level AnnotationLevel, err error) MultiAnnotateError {
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) != 0 {
+		annotations := make(MultiAnnotateError, 0, len(list))
+
+		for _, entry := range list {
+			annotations = append(annotations, NewAnnotation(level, entry.Msg, withPosition(entry.Pos)))
+		}
+
+		return annotations
+	}
+
+	return MultiAnnotateError{NewAnnotationFromGoError(level, err)}
+}
+
+func withPosition(pos token.Position) AnnotationOption {
+	return func(a *Annotation) {
+		a.File = pos.Filename
+		a.Line = pos.Line
+		a.Col = pos.Column
+	}
+}
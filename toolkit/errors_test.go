@@ -0,0 +1,277 @@
+package toolkit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_MultiAnnotateError(t *testing.T) {
+	err := MultiAnnotateError{NewError("first"), NewWarning("second"), NewError("third")}
+
+	t.Run("Error", func(t *testing.T) {
+		assert.EqualError(t, err, "3 annotations (2 error, 1 warning)")
+	})
+
+	t.Run("Emit", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		assert.NoError(t, err.Emit(buffer))
+		assert.Equal(t, "::error::first\n::warning::second\n::error::third\n", buffer.String())
+	})
+}
+
+func Test_EmitAndFail(t *testing.T) {
+	t.Run("No error", func(t *testing.T) {
+		var code int
+		got := capture(func() {
+			code = EmitAndFail(nil)
+		})
+
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "", got)
+	})
+
+	t.Run("MultiAnnotateError", func(t *testing.T) {
+		var code int
+		err := fmt.Errorf("validation: %w", MultiAnnotateError{NewError("first"), NewWarning("second")})
+		got := capture(func() {
+			code = EmitAndFail(err)
+		})
+
+		assert.Equal(t, 1, code)
+		assert.Equal(t, "::error::first\n::warning::second\n", got)
+	})
+
+	t.Run("Plain error", func(t *testing.T) {
+		var code int
+		got := capture(func() {
+			code = EmitAndFail(errors.New("failed"))
+		})
+
+		assert.Equal(t, 1, code)
+		assert.Equal(t, "::error::failed\n", got)
+	})
+}
+
+func Test_AnnotationError(t *testing.T) {
+	cause := errors.New("failed")
+	err := NewAnnotationError(NewError("failed").SetPosition("main.go", 5, 4), cause)
+
+	assert.EqualError(t, err, "failed")
+	assert.True(t, errors.Is(err, cause))
+
+	got := capture(func() {
+		assert.NoError(t, err.Emit())
+	})
+
+	assert.Equal(t, "::error file=main.go,line=5,col=4::failed\n", got)
+}
+
+func Test_AnnotationError_NilCause(t *testing.T) {
+	err := NewAnnotationError(NewError("failed"), nil)
+
+	assert.EqualError(t, err, "failed")
+	assert.Nil(t, errors.Unwrap(err))
+}
+
+func Test_EmitErrors(t *testing.T) {
+	inner := NewAnnotationError(NewError("inner").SetPosition("main.go", 1, 1), errors.New("inner"))
+	outer := NewAnnotationError(NewWarning("outer"), fmt.Errorf("context: %w", inner))
+	err := fmt.Errorf("wrapped: %w", outer)
+
+	got := capture(func() {
+		assert.NoError(t, EmitErrors(err))
+	})
+
+	assert.Equal(t, "::warning::outer\n::error file=main.go,line=1,col=1::inner\n", got)
+}
+
+func Test_Wrapf(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		var err error
+		got := capture(func() {
+			err = Wrapf(os.ErrNotExist, "failed to build %s", "cmd/action")
+		})
+
+		assert.Equal(t, "::error::failed to build cmd/action: file does not exist\n", got)
+		assert.EqualError(t, err, "failed to build cmd/action: file does not exist")
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		var err error
+		got := capture(func() {
+			err = Wrapf(nil, "failed to build %s", "cmd/action")
+		})
+
+		assert.Empty(t, got)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_SetExitCode(t *testing.T) {
+	defer SetExitCode(ExitSuccess)
+
+	assert.Equal(t, 0, ExitCodeOrZero())
+
+	SetExitCode(ExitFailure)
+	assert.Equal(t, 1, ExitCodeOrZero())
+
+	SetExitCode(ExitCode(78))
+	assert.Equal(t, 78, ExitCodeOrZero())
+}
+
+func Test_Recover(t *testing.T) {
+	code := -1
+	ExitFunc = func(c int) { code = c }
+	defer func() { ExitFunc = os.Exit }()
+
+	got := capture(func() {
+		defer Recover()
+		panic("boom")
+	})
+
+	assert.Equal(t, 1, code)
+	assert.True(t, strings.HasPrefix(got, "::error::panic: boom\n::debug::goroutine "))
+}
+
+func Test_Fatal(t *testing.T) {
+	code := -1
+	ExitFunc = func(c int) { code = c }
+	defer func() { ExitFunc = os.Exit }()
+
+	t.Run("Fatal", func(t *testing.T) {
+		got := capture(func() {
+			Fatal("unrecoverable")
+		})
+
+		assert.Equal(t, 1, code)
+		assert.Equal(t, "::error::unrecoverable\n", got)
+	})
+
+	t.Run("Fatalf", func(t *testing.T) {
+		code = -1
+		got := capture(func() {
+			Fatalf("missing %s", "config")
+		})
+
+		assert.Equal(t, 1, code)
+		assert.Equal(t, "::error::missing config\n", got)
+	})
+}
+
+func Test_NewAnnotationFromGoError(t *testing.T) {
+	t.Run("Parser error", func(t *testing.T) {
+		_, err := parser.ParseFile(token.NewFileSet(), "main.go", "package main\nfunc {", 0)
+		got := NewAnnotationFromGoError(LevelError, err)
+
+		assert.Equal(t, "main.go:2:6", got.SourceLocation())
+		assert.Equal(t, "::error file=main.go,line=2,col=6::expected 'IDENT', found '{'", got.String())
+	})
+
+	t.Run("Multiple parser errors", func(t *testing.T) {
+		got := NewAnnotationFromGoError(LevelError, parserErrors())
+
+		assert.Equal(t, "::error file=main.go,line=2,col=1::first (and 2 more errors)", got.String())
+	})
+
+	t.Run("Positioned error", func(t *testing.T) {
+		err := positionedError{token.Position{Filename: "main.go", Line: 3, Column: 1}}
+		got := NewAnnotationFromGoError(LevelWarning, err)
+
+		assert.Equal(t, "::warning file=main.go,line=3,col=1::positioned", got.String())
+	})
+
+	t.Run("Plain error", func(t *testing.T) {
+		got := NewAnnotationFromGoError(LevelError, errors.New("failed"))
+
+		assert.Equal(t, NewError("failed"), got)
+	})
+}
+
+func Test_NewAnnotationsFromGoError(t *testing.T) {
+	t.Run("Multiple parser errors", func(t *testing.T) {
+		got := NewAnnotationsFromGoError(LevelError, parserErrors())
+
+		assert.Equal(t, MultiAnnotateError{
+			NewError("first").SetPosition("main.go", 2, 1),
+			NewError("second").SetPosition("main.go", 3, 5),
+			NewError("third").SetPosition("util.go", 7, 2),
+		}, got)
+	})
+
+	t.Run("Plain error", func(t *testing.T) {
+		got := NewAnnotationsFromGoError(LevelError, errors.New("failed"))
+
+		assert.Equal(t, MultiAnnotateError{NewError("failed")}, got)
+	})
+}
+
+// parserErrors returns a list of errors like the ones returned by the go/parser package.
+func parserErrors() error {
+	var list scanner.ErrorList
+	list.Add(token.Position{Filename: "main.go", Line: 2, Column: 1}, "first")
+	list.Add(token.Position{Filename: "main.go", Line: 3, Column: 5}, "second")
+	list.Add(token.Position{Filename: "util.go", Line: 7, Column: 2}, "third")
+
+	return list
+}
+
+func Test_NewAnnotationFromError(t *testing.T) {
+	t.Run("PositionedError", func(t *testing.T) {
+		err := fmt.Errorf("build: %w", fileLineError{"config.yml", 12})
+		got := NewAnnotationFromError(LevelError, err)
+
+		assert.Equal(t, "::error file=config.yml,line=12::build: invalid key", got.String())
+	})
+
+	t.Run("Go error", func(t *testing.T) {
+		err := positionedError{token.Position{Filename: "main.go", Line: 3, Column: 1}}
+		got := NewAnnotationFromError(LevelWarning, err)
+
+		assert.Equal(t, "::warning file=main.go,line=3,col=1::positioned", got.String())
+	})
+
+	t.Run("Plain error", func(t *testing.T) {
+		got := NewAnnotationFromError(LevelError, errors.New("failed"))
+
+		assert.Equal(t, "::error::failed", got.String())
+	})
+}
+
+type fileLineError struct {
+	file string
+	line int
+}
+
+func (e fileLineError) Error() string {
+	return "invalid key"
+}
+
+func (e fileLineError) File() string {
+	return e.file
+}
+
+func (e fileLineError) Line() int {
+	return e.line
+}
+
+type positionedError struct {
+	pos token.Position
+}
+
+func (e positionedError) Error() string {
+	return "positioned"
+}
+
+func (e positionedError) Pos() token.Position {
+	return e.pos
+}
@@ -0,0 +1,238 @@
+package toolkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReadEventPayload reads and parses the JSON payload of the event which triggered the workflow.
+// The payload is read from the file referenced by the GITHUB_EVENT_PATH environment variable.
+func ReadEventPayload() (map[string]interface{}, error) {
+	return (&Metadata{EventPath: os.Getenv("GITHUB_EVENT_PATH")}).eventPayload()
+}
+
+// eventCache holds the raw event payload once it has been read by Metadata.EventJSON. Metadata
+// refers to it by pointer, so that copies of the metadata can be made freely and share the cache.
+type eventCache struct {
+	once sync.Once
+	raw  json.RawMessage
+	err  error
+}
+
+// EventJSON returns the raw JSON payload of the event which triggered the workflow, for callers who
+// want to unmarshal it into their own types. The payload is read from EventPath on the first call
+// and cached afterwards. Metadata which was not created by GetMetadata or Reload has no cache, and
+// reads the payload on every call.
+func (m *Metadata) EventJSON() (json.RawMessage, error) {
+	if m.event == nil {
+		return m.readEvent()
+	}
+
+	m.event.once.Do(func() {
+		m.event.raw, m.event.err = m.readEvent()
+	})
+
+	return m.event.raw, m.event.err
+}
+
+// readEvent reads the raw event payload from EventPath.
+func (m *Metadata) readEvent() (json.RawMessage, error) {
+	if len(m.EventPath) == 0 {
+		return nil, fmt.Errorf("Event payload not available, GITHUB_EVENT_PATH is not set")
+	}
+
+	return ioutil.ReadFile(m.EventPath)
+}
+
+// eventPayload parses the event payload returned by EventJSON, so that all helpers share its cache.
+func (m *Metadata) eventPayload() (map[string]interface{}, error) {
+	raw, err := m.EventJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// IsFork reports whether the workflow was triggered by a pull request from a fork, ie. whether the
+// head repository of the pull request differs from its base repository. Such workflows run in the
+// context of the base repository, so the repository fields of Metadata cannot tell them apart, but
+// they run untrusted code. A pull request whose head repository has been deleted is reported as a
+// fork as well. False is returned for events which are not related to a pull request.
+func (m *Metadata) IsFork() (bool, error) {
+	raw, err := m.EventJSON()
+	if err != nil {
+		return false, err
+	}
+
+	var payload struct {
+		PullRequest *struct {
+			Head struct {
+				Repo *struct {
+					FullName string `json:"full_name"`
+				} `json:"repo"`
+			} `json:"head"`
+			Base struct {
+				Repo struct {
+					FullName string `json:"full_name"`
+				} `json:"repo"`
+			} `json:"base"`
+		} `json:"pull_request"`
+	}
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return false, err
+	}
+
+	if payload.PullRequest == nil {
+		return false, nil
+	}
+
+	head := payload.PullRequest.Head.Repo
+
+	return head == nil || head.FullName != payload.PullRequest.Base.Repo.FullName, nil
+}
+
+// GetPullRequestNumber returns the number of the pull request which triggered the workflow.
+// An error is returned if the current event is not related to a pull request.
+func GetPullRequestNumber() (int, error) {
+	payload, err := ReadEventPayload()
+	if err != nil {
+		return 0, err
+	}
+
+	pr, ok := payload["pull_request"].(map[string]interface{})
+	if !ok {
+		return 0, fmt.Errorf("Event %s is not a pull request event", os.Getenv("GITHUB_EVENT_NAME"))
+	}
+
+	// JSON numbers are always decoded as float64 into an interface{}
+	number, ok := pr["number"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("Pull request number not present in event payload")
+	}
+
+	return int(number), nil
+}
+
+// GetWorkflowDispatchInputs returns the inputs supplied to a manually dispatched workflow, keyed by
+// their names as declared in the workflow. An error is returned if the current event is not a
+// workflow_dispatch event.
+func GetWorkflowDispatchInputs() (map[string]string, error) {
+	if name := os.Getenv("GITHUB_EVENT_NAME"); name != "workflow_dispatch" {
+		return nil, fmt.Errorf("Event %s is not a workflow_dispatch event", name)
+	}
+
+	payload, err := ReadEventPayload()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, _ := payload["inputs"].(map[string]interface{})
+	inputs := make(map[string]string, len(raw))
+
+	for name, value := range raw {
+		if value == nil {
+			continue
+		}
+
+		inputs[name] = fmt.Sprint(value)
+	}
+
+	return inputs, nil
+}
+
+// DeploymentID returns the ID of the deployment which triggered the workflow.
+// An error is returned if the current event is not related to a deployment.
+func (m *Metadata) DeploymentID() (int64, error) {
+	if m.EventName != "deployment" && m.EventName != "deployment_status" {
+		return 0, fmt.Errorf("Event %s is not a deployment event", m.EventName)
+	}
+
+	payload, err := m.eventPayload()
+	if err != nil {
+		return 0, err
+	}
+
+	deployment, _ := payload["deployment"].(map[string]interface{})
+	id, ok := deployment["id"].(float64)
+
+	if !ok {
+		return 0, fmt.Errorf("Deployment ID not present in event payload")
+	}
+
+	return int64(id), nil
+}
+
+// PreviousSHA returns the SHA of the most recent commit on the ref before the push which triggered
+// the workflow. An error is returned if the current event is not a push.
+func (m *Metadata) PreviousSHA() (string, error) {
+	if m.EventName != "push" {
+		return "", fmt.Errorf("Event %s is not a push event", m.EventName)
+	}
+
+	payload, err := m.eventPayload()
+	if err != nil {
+		return "", err
+	}
+
+	before, ok := payload["before"].(string)
+	if !ok {
+		return "", fmt.Errorf("Previous SHA not present in event payload")
+	}
+
+	return before, nil
+}
+
+// RunCreatedAt returns the time at which the workflow run was created. The time is read from the
+// GITHUB_RUN_CREATED_AT environment variable on runners which provide it, otherwise from the
+// created_at field of the workflow run or of the event payload itself.
+func (m *Metadata) RunCreatedAt() (time.Time, error) {
+	if value := os.Getenv("GITHUB_RUN_CREATED_AT"); len(value) != 0 {
+		return time.Parse(time.RFC3339, value)
+	}
+
+	payload, err := m.eventPayload()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	value, ok := payload["created_at"].(string)
+
+	if run, isRun := payload["workflow_run"].(map[string]interface{}); isRun {
+		value, ok = run["created_at"].(string)
+	}
+
+	if !ok {
+		return time.Time{}, fmt.Errorf("Run creation time not available for event %s", m.EventName)
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// EventActor returns the login of the user who triggered the event, taken from the sender of the
+// event payload. This can differ from Actor, eg. for a label added to another user's pull request.
+// Actor is returned if the payload does not contain a sender.
+func (m *Metadata) EventActor() (string, error) {
+	payload, err := m.eventPayload()
+	if err != nil {
+		return "", err
+	}
+
+	sender, _ := payload["sender"].(map[string]interface{})
+
+	if login, ok := sender["login"].(string); ok && len(login) != 0 {
+		return login, nil
+	}
+
+	return m.Actor, nil
+}
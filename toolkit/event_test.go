@@ -0,0 +1,334 @@
+package toolkit
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_ReadEventPayload(t *testing.T) {
+	t.Run("Valid payload", func(t *testing.T) {
+		defer withEvent(t, "push", `{"ref": "refs/heads/main"}`)()
+
+		payload, err := ReadEventPayload()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "refs/heads/main", payload["ref"])
+	})
+
+	t.Run("Missing event path", func(t *testing.T) {
+		path := os.Getenv("GITHUB_EVENT_PATH")
+		os.Unsetenv("GITHUB_EVENT_PATH")
+		defer os.Setenv("GITHUB_EVENT_PATH", path)
+
+		_, err := ReadEventPayload()
+
+		assert.EqualError(t, err, "Event payload not available, GITHUB_EVENT_PATH is not set")
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		defer withEvent(t, "push", `{`)()
+
+		_, err := ReadEventPayload()
+
+		assert.Error(t, err)
+	})
+}
+
+func Test_EventJSON(t *testing.T) {
+	t.Run("Cached", func(t *testing.T) {
+		restore := withEvent(t, "push", `{"ref": "refs/heads/main"}`)
+		meta := GetMetadata()
+
+		var payload struct {
+			Ref string `json:"ref"`
+		}
+
+		raw, err := meta.EventJSON()
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(raw, &payload))
+		assert.Equal(t, "refs/heads/main", payload.Ref)
+
+		restore()
+
+		cached, err := meta.EventJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, raw, cached)
+	})
+
+	t.Run("Copied metadata shares the cache", func(t *testing.T) {
+		restore := withEvent(t, "push", `{"ref": "refs/heads/main"}`)
+		meta := *GetMetadata()
+
+		raw, err := meta.EventJSON()
+		assert.NoError(t, err)
+
+		restore()
+
+		copied := meta
+		cached, err := copied.EventJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, raw, cached)
+	})
+
+	t.Run("Not cached without GetMetadata", func(t *testing.T) {
+		restore := withEvent(t, "push", `{"ref": "refs/heads/main"}`)
+		meta := &Metadata{EventPath: os.Getenv("GITHUB_EVENT_PATH")}
+
+		_, err := meta.EventJSON()
+		assert.NoError(t, err)
+
+		restore()
+
+		_, err = meta.EventJSON()
+		assert.Error(t, err)
+	})
+
+	t.Run("Payload not available", func(t *testing.T) {
+		_, err := (&Metadata{}).EventJSON()
+
+		assert.EqualError(t, err, "Event payload not available, GITHUB_EVENT_PATH is not set")
+	})
+}
+
+func Test_IsFork(t *testing.T) {
+	cases := []struct {
+		name    string
+		event   string
+		payload string
+		want    bool
+	}{
+		{"Same repository", "pull_request", `{"pull_request": {"head": {"repo": {"full_name": "octocat/hello-world"}}, "base": {"repo": {"full_name": "octocat/hello-world"}}}}`, false},
+		{"Fork", "pull_request", `{"pull_request": {"head": {"repo": {"full_name": "contributor/hello-world"}}, "base": {"repo": {"full_name": "octocat/hello-world"}}}}`, true},
+		{"Deleted fork", "pull_request_target", `{"pull_request": {"head": {"repo": null}, "base": {"repo": {"full_name": "octocat/hello-world"}}}}`, true},
+		{"Not a pull request", "push", `{"ref": "refs/heads/main"}`, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer withEvent(t, c.event, c.payload)()
+
+			got, err := GetMetadata().IsFork()
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+
+	t.Run("Payload not available", func(t *testing.T) {
+		_, err := (&Metadata{}).IsFork()
+
+		assert.EqualError(t, err, "Event payload not available, GITHUB_EVENT_PATH is not set")
+	})
+}
+
+func Test_GetPullRequestNumber(t *testing.T) {
+	t.Run("Pull request event", func(t *testing.T) {
+		defer withEvent(t, "pull_request", `{"pull_request": {"number": 42}}`)()
+
+		got, err := GetPullRequestNumber()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 42, got)
+	})
+
+	t.Run("Push event", func(t *testing.T) {
+		defer withEvent(t, "push", `{"ref": "refs/heads/main"}`)()
+
+		got, err := GetPullRequestNumber()
+
+		assert.Equal(t, 0, got)
+		assert.EqualError(t, err, "Event push is not a pull request event")
+	})
+}
+
+func Test_GetWorkflowDispatchInputs(t *testing.T) {
+	t.Run("Workflow dispatch event", func(t *testing.T) {
+		defer withEvent(t, "workflow_dispatch", `{"inputs": {"environment": "staging", "dry-run": true, "empty": null}}`)()
+
+		got, err := GetWorkflowDispatchInputs()
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"environment": "staging", "dry-run": "true"}, got)
+	})
+
+	t.Run("No inputs", func(t *testing.T) {
+		defer withEvent(t, "workflow_dispatch", `{}`)()
+
+		got, err := GetWorkflowDispatchInputs()
+
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Push event", func(t *testing.T) {
+		defer withEvent(t, "push", `{"ref": "refs/heads/main"}`)()
+
+		_, err := GetWorkflowDispatchInputs()
+
+		assert.EqualError(t, err, "Event push is not a workflow_dispatch event")
+	})
+}
+
+func Test_DeploymentID(t *testing.T) {
+	t.Run("Deployment event", func(t *testing.T) {
+		defer withEvent(t, "deployment", `{"deployment": {"id": 1234567890}}`)()
+
+		got, err := GetMetadata().DeploymentID()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1234567890), got)
+	})
+
+	t.Run("Missing deployment", func(t *testing.T) {
+		defer withEvent(t, "deployment_status", `{}`)()
+
+		_, err := GetMetadata().DeploymentID()
+
+		assert.EqualError(t, err, "Deployment ID not present in event payload")
+	})
+
+	t.Run("Push event", func(t *testing.T) {
+		defer withEvent(t, "push", `{"ref": "refs/heads/main"}`)()
+
+		_, err := GetMetadata().DeploymentID()
+
+		assert.EqualError(t, err, "Event push is not a deployment event")
+	})
+}
+
+func Test_PreviousSHA(t *testing.T) {
+	t.Run("Push event", func(t *testing.T) {
+		defer withEvent(t, "push", `{"before": "6113728f27ae82c7b1a177c8d03f9e96e0adf246"}`)()
+
+		got, err := GetMetadata().PreviousSHA()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "6113728f27ae82c7b1a177c8d03f9e96e0adf246", got)
+	})
+
+	t.Run("Cached payload", func(t *testing.T) {
+		restore := withEvent(t, "push", `{"before": "6113728f27ae82c7b1a177c8d03f9e96e0adf246", "sender": {"login": "monalisa"}}`)
+		meta := GetMetadata()
+
+		_, err := meta.EventJSON()
+		assert.NoError(t, err)
+
+		restore()
+
+		got, err := meta.PreviousSHA()
+		assert.NoError(t, err)
+		assert.Equal(t, "6113728f27ae82c7b1a177c8d03f9e96e0adf246", got)
+
+		actor, err := meta.EventActor()
+		assert.NoError(t, err)
+		assert.Equal(t, "monalisa", actor)
+	})
+
+	t.Run("Missing before", func(t *testing.T) {
+		defer withEvent(t, "push", `{}`)()
+
+		_, err := GetMetadata().PreviousSHA()
+
+		assert.EqualError(t, err, "Previous SHA not present in event payload")
+	})
+
+	t.Run("Pull request event", func(t *testing.T) {
+		defer withEvent(t, "pull_request", `{"pull_request": {"number": 1}}`)()
+
+		_, err := GetMetadata().PreviousSHA()
+
+		assert.EqualError(t, err, "Event pull_request is not a push event")
+	})
+}
+
+func Test_RunCreatedAt(t *testing.T) {
+	want := time.Date(2020, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	t.Run("Environment", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_RUN_CREATED_AT")
+		os.Setenv("GITHUB_RUN_CREATED_AT", "2020-03-01T12:30:00Z")
+
+		got, err := (&Metadata{}).RunCreatedAt()
+
+		assert.NoError(t, err)
+		assert.True(t, want.Equal(got))
+	})
+
+	t.Run("Workflow run event", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_RUN_CREATED_AT")
+		os.Unsetenv("GITHUB_RUN_CREATED_AT")
+		defer withEvent(t, "workflow_run", `{"workflow_run": {"created_at": "2020-03-01T12:30:00Z"}}`)()
+
+		got, err := GetMetadata().RunCreatedAt()
+
+		assert.NoError(t, err)
+		assert.True(t, want.Equal(got))
+	})
+
+	t.Run("Not available", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_RUN_CREATED_AT")
+		os.Unsetenv("GITHUB_RUN_CREATED_AT")
+		defer withEvent(t, "push", `{"ref": "refs/heads/main"}`)()
+
+		_, err := GetMetadata().RunCreatedAt()
+
+		assert.EqualError(t, err, "Run creation time not available for event push")
+	})
+}
+
+func Test_EventActor(t *testing.T) {
+	t.Run("Sender", func(t *testing.T) {
+		defer withEvent(t, "pull_request", `{"sender": {"login": "octocat"}}`)()
+
+		meta := GetMetadata()
+		meta.Actor = "monalisa"
+		got, err := meta.EventActor()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "octocat", got)
+	})
+
+	t.Run("Missing sender", func(t *testing.T) {
+		defer withEvent(t, "schedule", `{"schedule": "0 0 * * *"}`)()
+
+		meta := GetMetadata()
+		meta.Actor = "monalisa"
+		got, err := meta.EventActor()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "monalisa", got)
+	})
+
+	t.Run("Payload not available", func(t *testing.T) {
+		_, err := (&Metadata{Actor: "monalisa"}).EventActor()
+
+		assert.EqualError(t, err, "Event payload not available, GITHUB_EVENT_PATH is not set")
+	})
+}
+
+// withEvent writes the given payload to a temporary file and points the event environment
+// variables at it. The returned function restores the original environment.
+func withEvent(t *testing.T, name string, payload string) func() {
+	file, err := ioutil.TempFile("", "event-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.WriteString(payload)
+	file.Close()
+
+	originalName := os.Getenv("GITHUB_EVENT_NAME")
+	originalPath := os.Getenv("GITHUB_EVENT_PATH")
+	os.Setenv("GITHUB_EVENT_NAME", name)
+	os.Setenv("GITHUB_EVENT_PATH", file.Name())
+
+	return func() {
+		os.Setenv("GITHUB_EVENT_NAME", originalName)
+		os.Setenv("GITHUB_EVENT_PATH", originalPath)
+		os.Remove(file.Name())
+	}
+}
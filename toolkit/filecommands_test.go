@@ -0,0 +1,120 @@
+package toolkit_test
+
+import (
+	"github.com/robertrossmann/actions/toolkit"
+	"github.com/robertrossmann/actions/toolkit/testutil"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// The tests in this file use the runner's files and thus testutil, which imports the toolkit
+// package and can only be used from its external test package.
+
+func Test_SetOutput_File(t *testing.T) {
+	runner := testutil.NewFakeRunner(t)
+
+	_, err := toolkit.SetOutput("testkey", "testvalue")
+
+	assert.NoError(t, err)
+	assert.Empty(t, runner.CapturedOutput())
+	assert.Equal(t, "testkey<<ghadelimiter\ntestvalue\nghadelimiter\n", read(t, runner.Output))
+}
+
+func Test_Setenv_File(t *testing.T) {
+	runner := testutil.NewFakeRunner(t)
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	_, _, err := toolkit.Setenv("TEST_ENV_VAR", "two\nlines")
+
+	assert.NoError(t, err)
+	assert.Empty(t, runner.CapturedOutput())
+	assert.Equal(t, "TEST_ENV_VAR<<ghadelimiter\ntwo\nlines\nghadelimiter\n", read(t, runner.Env))
+	assert.Equal(t, "two\nlines", os.Getenv("TEST_ENV_VAR"))
+}
+
+func Test_PrependPath_File(t *testing.T) {
+	runner := testutil.NewFakeRunner(t)
+
+	_, err := toolkit.PrependPath("/usr/dummy/bin")
+
+	assert.NoError(t, err)
+	assert.Empty(t, runner.CapturedOutput())
+	assert.Equal(t, "/usr/dummy/bin\n", read(t, runner.Path))
+}
+
+func Test_Batch_Files(t *testing.T) {
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	t.Run("Commit", func(t *testing.T) {
+		runner := testutil.NewFakeRunner(t)
+
+		err := toolkit.NewBatch().
+			SetOutput("first", "one").
+			SetOutput("second", "two\nlines").
+			Setenv("TEST_ENV_VAR", "testvalue").
+			PrependPath("/usr/first/bin").
+			PrependPath("/usr/second/bin").
+			Commit()
+
+		assert.NoError(t, err)
+		assert.Empty(t, runner.CapturedOutput())
+		assert.Equal(t, "first<<ghadelimiter\none\nghadelimiter\nsecond<<ghadelimiter\ntwo\nlines\nghadelimiter\n", read(t, runner.Output))
+		assert.Equal(t, "TEST_ENV_VAR<<ghadelimiter\ntestvalue\nghadelimiter\n", read(t, runner.Env))
+		assert.Equal(t, "/usr/first/bin\n/usr/second/bin\n", read(t, runner.Path))
+		assert.Equal(t, "testvalue", os.Getenv("TEST_ENV_VAR"))
+		assert.True(t, strings.HasPrefix(os.Getenv("PATH"), "/usr/second/bin"+string(os.PathListSeparator)+"/usr/first/bin"))
+	})
+
+	t.Run("Invalid output", func(t *testing.T) {
+		runner := testutil.NewFakeRunner(t)
+
+		err := toolkit.NewBatch().
+			SetOutput("first", "one").
+			SetOutput("second", strings.Repeat("a", toolkit.MaxOutputSize+1)).
+			Commit()
+
+		assert.Equal(t, toolkit.ErrOutputTooLarge, err)
+		assert.Empty(t, read(t, runner.Output))
+	})
+
+	t.Run("Invalid environment variable name", func(t *testing.T) {
+		runner := testutil.NewFakeRunner(t)
+
+		err := toolkit.NewBatch().
+			SetOutput("first", "one").
+			Setenv("MY VAR", "testvalue").
+			Commit()
+
+		assert.Equal(t, toolkit.ErrInvalidEnvName, err)
+		assert.Empty(t, read(t, runner.Output))
+		assert.Empty(t, read(t, runner.Env))
+	})
+
+	t.Run("Relative path", func(t *testing.T) {
+		runner := testutil.NewFakeRunner(t)
+
+		err := toolkit.NewBatch().
+			SetOutput("first", "one").
+			PrependPath("/usr/absolute/bin").
+			PrependPath("bin").
+			Commit()
+
+		assert.Equal(t, toolkit.ErrRelativePath, err)
+		assert.Empty(t, read(t, runner.Output))
+		assert.Empty(t, read(t, runner.Path))
+		assert.NotContains(t, os.Getenv("PATH"), "/usr/absolute/bin")
+	})
+}
+
+// read returns the contents of the file at path.
+func read(t *testing.T, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(contents)
+}
@@ -0,0 +1,84 @@
+// Package format implements helpers which build GitHub Flavored Markdown fragments, eg. for step
+// summaries, annotations or pull request comments.
+package format
+
+import (
+	"strings"
+)
+
+// linkText escapes the characters which would end the text of a link or image early.
+var linkText = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// linkURL encodes the characters which would end the destination of a link or image early.
+var linkURL = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// Bold returns text in strong emphasis.
+func Bold(text string) string {
+	return "**" + text + "**"
+}
+
+// Italic returns text in emphasis.
+func Italic(text string) string {
+	return "_" + text + "_"
+}
+
+// Code returns text as an inline code span. The span is delimited by more backticks than text
+// contains in a row, so that backticks in text are rendered as they are.
+func Code(text string) string {
+	fence := strings.Repeat("`", longestRun(text, '`')+1)
+
+	// A space is stripped from both ends of the span, so that text can start or end with a backtick
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+
+	return fence + text + fence
+}
+
+// CodeBlock returns text as a fenced code block highlighted as lang, which may be empty. The fence
+// is longer than any run of backticks in text, so that text cannot end the block early.
+func CodeBlock(lang string, text string) string {
+	length := longestRun(text, '`') + 1
+
+	if length < 3 {
+		length = 3
+	}
+
+	fence := strings.Repeat("`", length)
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	return fence + lang + "\n" + text + fence + "\n"
+}
+
+// Link returns a link to url with the given text.
+func Link(text string, url string) string {
+	return "[" + linkText.Replace(text) + "](" + linkURL.Replace(url) + ")"
+}
+
+// Image returns an image loaded from url with the given alternative text.
+func Image(alt string, url string) string {
+	return "!" + Link(alt, url)
+}
+
+// longestRun returns the length of the longest run of c in text.
+func longestRun(text string, c byte) int {
+	longest, current := 0, 0
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != c {
+			current = 0
+			continue
+		}
+
+		current++
+
+		if current > longest {
+			longest = current
+		}
+	}
+
+	return longest
+}
@@ -0,0 +1,57 @@
+package format
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Bold(t *testing.T) {
+	assert.Equal(t, "**passed**", Bold("passed"))
+}
+
+func Test_Italic(t *testing.T) {
+	assert.Equal(t, "_skipped_", Italic("skipped"))
+}
+
+func Test_Code(t *testing.T) {
+	t.Run("Plain", func(t *testing.T) {
+		assert.Equal(t, "`go test ./...`", Code("go test ./..."))
+	})
+
+	t.Run("Backticks", func(t *testing.T) {
+		assert.Equal(t, "``a ` b``", Code("a ` b"))
+		assert.Equal(t, "```a `` b```", Code("a `` b"))
+	})
+
+	t.Run("Leading backtick", func(t *testing.T) {
+		assert.Equal(t, "`` `quoted` ``", Code("`quoted`"))
+	})
+}
+
+func Test_CodeBlock(t *testing.T) {
+	t.Run("Language", func(t *testing.T) {
+		assert.Equal(t, "```go\nfunc main() {}\n```\n", CodeBlock("go", "func main() {}"))
+	})
+
+	t.Run("Trailing newline", func(t *testing.T) {
+		assert.Equal(t, "```\nok\n```\n", CodeBlock("", "ok\n"))
+	})
+
+	t.Run("Nested fence", func(t *testing.T) {
+		assert.Equal(t, "````markdown\n```go\n```\n````\n", CodeBlock("markdown", "```go\n```"))
+	})
+}
+
+func Test_Link(t *testing.T) {
+	t.Run("Plain", func(t *testing.T) {
+		assert.Equal(t, "[Workflow](https://github.com/octocat/hello-world/actions)", Link("Workflow", "https://github.com/octocat/hello-world/actions"))
+	})
+
+	t.Run("Escaped", func(t *testing.T) {
+		assert.Equal(t, `[\[draft\] Fix](https://example.com/a%20b%28c%29)`, Link("[draft] Fix", "https://example.com/a b(c)"))
+	})
+}
+
+func Test_Image(t *testing.T) {
+	assert.Equal(t, "![Coverage](https://example.com/badge.svg)", Image("Coverage", "https://example.com/badge.svg"))
+}
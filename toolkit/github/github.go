@@ -0,0 +1,104 @@
+// Package github implements a minimal client for the GitHub REST API, authenticated with the token
+// passed to the action.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/robertrossmann/actions/toolkit"
+)
+
+// DefaultBaseURL is the URL of the GitHub REST API used when GITHUB_API_URL is not set.
+const DefaultBaseURL = "https://api.github.com"
+
+// Client performs authenticated requests against the GitHub REST API.
+type Client struct {
+	// BaseURL is the URL of the API, without a trailing slash.
+	BaseURL string
+	// HTTPClient is used to perform the requests.
+	HTTPClient *http.Client
+
+	token string
+}
+
+// NewClient creates a Client authenticated with GITHUB_TOKEN for the API at GITHUB_API_URL, which
+// defaults to DefaultBaseURL. An error is returned if the token is not available.
+func NewClient() (*Client, error) {
+	meta := toolkit.GetMetadata()
+
+	if len(meta.Token) == 0 {
+		return nil, fmt.Errorf("GitHub token not available, GITHUB_TOKEN is not set")
+	}
+
+	baseURL := meta.APIURL
+
+	if len(baseURL) == 0 {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		token:      meta.Token,
+	}, nil
+}
+
+// NewRequest creates a request for the given path, relative to BaseURL. If body is not nil, it is
+// encoded as JSON. The authentication, Accept and User-Agent headers are set automatically.
+func (c *Client) NewRequest(ctx context.Context, method string, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+"/"+strings.TrimPrefix(path, "/"), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", toolkit.UserAgent())
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// Do sends req and decodes the JSON response body into v, unless v is nil. An error is returned for
+// responses with a status other than 2xx.
+func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res, fmt.Errorf("GitHub API request %s %s failed with status %s", req.Method, req.URL.Path, res.Status)
+	}
+
+	if v != nil && res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
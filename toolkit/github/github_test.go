@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"github.com/robertrossmann/actions/toolkit"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func Test_NewClient(t *testing.T) {
+	t.Run("Token not available", func(t *testing.T) {
+		withEnv(t, "GITHUB_TOKEN", "")
+
+		_, err := NewClient()
+
+		assert.EqualError(t, err, "GitHub token not available, GITHUB_TOKEN is not set")
+	})
+
+	t.Run("Default base URL", func(t *testing.T) {
+		withEnv(t, "GITHUB_TOKEN", "secret")
+		withEnv(t, "GITHUB_API_URL", "")
+
+		client, err := NewClient()
+
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultBaseURL, client.BaseURL)
+	})
+
+	t.Run("GITHUB_API_URL", func(t *testing.T) {
+		withEnv(t, "GITHUB_TOKEN", "secret")
+		withEnv(t, "GITHUB_API_URL", "https://github.example.com/api/v3/")
+
+		client, err := NewClient()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://github.example.com/api/v3", client.BaseURL)
+	})
+}
+
+func Test_Client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/octocat/hello-world" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		assert.Equal(t, toolkit.UserAgent(), r.Header.Get("User-Agent"))
+
+		w.Write([]byte(`{"full_name": "octocat/hello-world"}`))
+	}))
+	defer server.Close()
+
+	withEnv(t, "GITHUB_TOKEN", "secret")
+	withEnv(t, "GITHUB_API_URL", server.URL)
+
+	client, err := NewClient()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		req, err := client.NewRequest(context.Background(), http.MethodGet, "/repos/octocat/hello-world", nil)
+		assert.NoError(t, err)
+
+		var repo struct {
+			FullName string `json:"full_name"`
+		}
+
+		_, err = client.Do(req, &repo)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "octocat/hello-world", repo.FullName)
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		req, err := client.NewRequest(context.Background(), http.MethodGet, "repos/octocat/missing", nil)
+		assert.NoError(t, err)
+
+		res, err := client.Do(req, nil)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.EqualError(t, err, "GitHub API request GET /repos/octocat/missing failed with status 404 Not Found")
+	})
+}
+
+// withEnv sets an environment variable for the duration of the test.
+func withEnv(t *testing.T, key string, value string) {
+	original, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
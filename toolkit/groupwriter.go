@@ -0,0 +1,48 @@
+package toolkit
+
+import (
+	"fmt"
+	"io"
+)
+
+// groupWriter wraps a writer in an output group which starts with the first byte written.
+type groupWriter struct {
+	name    string
+	w       io.Writer
+	started bool
+}
+
+// NewGroupWriter wraps w so that everything written to it is placed in an output group with the
+// given name. The group is started when the first byte is written and ended when the writer is
+// closed. Nothing is emitted if the writer is closed without writing anything, so that no empty
+// groups show up in the log. Closing the returned writer does not close w.
+func NewGroupWriter(name string, w io.Writer) io.WriteCloser {
+	return &groupWriter{name: name, w: w}
+}
+
+func (g *groupWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if !g.started {
+		if _, err := fmt.Fprintf(g.w, "::group::%s\n", g.name); err != nil {
+			return 0, err
+		}
+
+		g.started = true
+	}
+
+	return g.w.Write(p)
+}
+
+func (g *groupWriter) Close() error {
+	if !g.started {
+		return nil
+	}
+
+	g.started = false
+	_, err := fmt.Fprintln(g.w, "::endgroup")
+
+	return err
+}
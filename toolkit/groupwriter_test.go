@@ -0,0 +1,29 @@
+package toolkit
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NewGroupWriter(t *testing.T) {
+	t.Run("With output", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		w := NewGroupWriter("Build Output", buffer)
+
+		w.Write([]byte("first\n"))
+		w.Write([]byte("second\n"))
+		w.Close()
+
+		assert.Equal(t, "::group::Build Output\nfirst\nsecond\n::endgroup\n", buffer.String())
+	})
+
+	t.Run("Without output", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		w := NewGroupWriter("Build Output", buffer)
+
+		w.Close()
+
+		assert.Empty(t, buffer.String())
+	})
+}
@@ -0,0 +1,98 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// idTokenRetries is the number of times a failed ID token request is retried.
+const idTokenRetries = 3
+
+// idTokenBackoff is the delay before the first retry; it doubles with every following retry.
+var idTokenBackoff = 100 * time.Millisecond
+
+// GetIDToken requests an OpenID Connect ID token for the job from GitHub's OIDC provider. The
+// audience is optional. The job must have the id-token: write permission.
+//
+// Requests failing due to network errors or server errors are retried up to 3 times with
+// exponential backoff. Cancelling ctx stops any further retries.
+func GetIDToken(ctx context.Context, audience string) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+
+	if len(requestURL) == 0 || len(requestToken) == 0 {
+		return "", fmt.Errorf("ID token not available, is the id-token: write permission set?")
+	}
+
+	if len(audience) != 0 {
+		requestURL += "&audience=" + url.QueryEscape(audience)
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, retry, err := requestIDToken(ctx, requestURL, requestToken)
+
+		if err == nil || !retry || attempt == idTokenRetries {
+			return token, err
+		}
+
+		Debug(fmt.Sprintf("Retrying ID token request after error: %s", err))
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff(attempt)):
+		}
+	}
+}
+
+// requestIDToken performs a single ID token request and reports whether a failure can be retried.
+func requestIDToken(ctx context.Context, requestURL string, requestToken string) (string, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent())
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", ctx.Err() == nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", res.StatusCode >= 500, fmt.Errorf("ID token request failed with status %s", res.Status)
+	}
+
+	body := struct {
+		Value string `json:"value"`
+	}{}
+
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", false, err
+	}
+
+	if len(body.Value) == 0 {
+		return "", false, fmt.Errorf("ID token response did not contain a token")
+	}
+
+	return body.Value, false, nil
+}
+
+// backoff returns the delay before the given retry attempt, with ±25% jitter.
+func backoff(attempt int) time.Duration {
+	delay := idTokenBackoff << uint(attempt)
+	jitter := (rand.Float64()*0.5 - 0.25) * float64(delay)
+
+	return delay + time.Duration(jitter)
+}
@@ -0,0 +1,132 @@
+package toolkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_GetIDToken(t *testing.T) {
+	original := idTokenBackoff
+	idTokenBackoff = time.Millisecond
+	defer func() { idTokenBackoff = original }()
+
+	t.Run("Success", func(t *testing.T) {
+		withIDTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer request-token", r.Header.Get("Authorization"))
+			assert.Equal(t, UserAgent(), r.Header.Get("User-Agent"))
+			assert.Equal(t, "sts.amazonaws.com", r.URL.Query().Get("audience"))
+			fmt.Fprint(w, `{"value": "id-token"}`)
+		})
+
+		got, err := GetIDToken(context.Background(), "sts.amazonaws.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "id-token", got)
+	})
+
+	t.Run("Retries server errors", func(t *testing.T) {
+		attempts := 0
+		withIDTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if attempts++; attempts < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			fmt.Fprint(w, `{"value": "id-token"}`)
+		})
+
+		var token string
+		var err error
+		got := capture(func() {
+			token, err = GetIDToken(context.Background(), "")
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "id-token", token)
+		assert.Equal(t, 3, attempts)
+		assert.Contains(t, got, "::debug::Retrying ID token request after error: ID token request failed with status 502 Bad Gateway\n")
+	})
+
+	t.Run("Gives up after 3 retries", func(t *testing.T) {
+		attempts := 0
+		withIDTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		var err error
+		capture(func() {
+			_, err = GetIDToken(context.Background(), "")
+		})
+
+		assert.EqualError(t, err, "ID token request failed with status 500 Internal Server Error")
+		assert.Equal(t, 4, attempts)
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		attempts := 0
+		withIDTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusForbidden)
+		})
+
+		_, err := GetIDToken(context.Background(), "")
+
+		assert.EqualError(t, err, "ID token request failed with status 403 Forbidden")
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		withIDTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			cancel()
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		var err error
+		capture(func() {
+			_, err = GetIDToken(ctx, "")
+		})
+
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Not available", func(t *testing.T) {
+		restoreEnv(t, "ACTIONS_ID_TOKEN_REQUEST_URL")
+		os.Unsetenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+
+		_, err := GetIDToken(context.Background(), "")
+
+		assert.EqualError(t, err, "ID token not available, is the id-token: write permission set?")
+	})
+}
+
+func Test_backoff(t *testing.T) {
+	for attempt, base := range []time.Duration{100, 200, 400} {
+		delay := backoff(attempt)
+		base *= time.Millisecond
+
+		assert.True(t, delay >= base*3/4 && delay <= base*5/4, "attempt %d: %s", attempt, delay)
+	}
+}
+
+// withIDTokenServer starts a server handling ID token requests and points the environment at it.
+func withIDTokenServer(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	restoreEnv(t, "ACTIONS_ID_TOKEN_REQUEST_URL")
+	restoreEnv(t, "ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	os.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL+"?api-version=2.0")
+	os.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+}
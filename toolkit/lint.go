@@ -0,0 +1,89 @@
+package toolkit
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// LintIssue can be implemented by lint result types to be converted by NewAnnotationFromLintIssue.
+type LintIssue interface {
+	Position() token.Position
+	Severity() string
+	Text() string
+}
+
+// lintLevels maps the severities reported by lint tools to annotation levels. Unknown or empty
+// severities are reported as warnings.
+var lintLevels = map[string]AnnotationLevel{
+	"error":   LevelError,
+	"warning": LevelWarning,
+	"info":    LevelNotice,
+	"notice":  LevelNotice,
+	"debug":   LevelDebug,
+}
+
+// NewAnnotationFromLintIssue creates an annotation from a lint issue. The issue may implement
+// LintIssue, or be a struct (or a pointer to one) like those produced by golangci-lint and
+// staticcheck: the position is taken from its Pos or Position token.Position field, or from its
+// Filename, Line and Column fields, and the message from its Message or Text field. The level is
+// derived from the Severity field and defaults to a warning.
+func NewAnnotationFromLintIssue(issue interface{}) (Annotation, error) {
+	if i, ok := issue.(LintIssue); ok {
+		return NewAnnotation(lintLevel(i.Severity()), i.Text(), withPosition(i.Position())), nil
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(issue))
+
+	if value.Kind() != reflect.Struct {
+		return Annotation{}, fmt.Errorf("Unsupported lint issue type %T", issue)
+	}
+
+	message, ok := stringField(value, "Message", "Text")
+	if !ok {
+		return Annotation{}, fmt.Errorf("Lint issue %T has no message", issue)
+	}
+
+	severity, _ := stringField(value, "Severity")
+	pos := token.Position{}
+
+	if field := value.FieldByName("Pos"); field.IsValid() && field.Type() == reflect.TypeOf(pos) {
+		pos = field.Interface().(token.Position)
+	} else if field := value.FieldByName("Position"); field.IsValid() && field.Type() == reflect.TypeOf(pos) {
+		pos = field.Interface().(token.Position)
+	} else {
+		pos.Filename, _ = stringField(value, "Filename")
+		pos.Line = intField(value, "Line")
+		pos.Column = intField(value, "Column")
+	}
+
+	return NewAnnotation(lintLevel(severity), message, withPosition(pos)), nil
+}
+
+func lintLevel(severity string) AnnotationLevel {
+	if level, ok := lintLevels[strings.ToLower(severity)]; ok {
+		return level
+	}
+
+	return LevelWarning
+}
+
+// stringField returns the value of the first of the named string fields present on value.
+func stringField(value reflect.Value, names ...string) (string, bool) {
+	for _, name := range names {
+		if field := value.FieldByName(name); field.IsValid() && field.Kind() == reflect.String {
+			return field.String(), true
+		}
+	}
+
+	return "", false
+}
+
+func intField(value reflect.Value, name string) int {
+	if field := value.FieldByName(name); field.IsValid() && field.Kind() == reflect.Int {
+		return int(field.Int())
+	}
+
+	return 0
+}
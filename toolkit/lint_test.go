@@ -0,0 +1,66 @@
+package toolkit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/token"
+	"testing"
+)
+
+type customIssue struct{}
+
+func (customIssue) Position() token.Position {
+	return token.Position{Filename: "main.go", Line: 4, Column: 2}
+}
+
+func (customIssue) Severity() string { return "error" }
+
+func (customIssue) Text() string { return "custom issue" }
+
+func Test_NewAnnotationFromLintIssue(t *testing.T) {
+	t.Run("LintIssue", func(t *testing.T) {
+		got, err := NewAnnotationFromLintIssue(customIssue{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "::error file=main.go,line=4,col=2::custom issue", got.String())
+	})
+
+	t.Run("Position field", func(t *testing.T) {
+		issue := struct {
+			Pos        token.Position
+			Text       string
+			FromLinter string
+		}{token.Position{Filename: "main.go", Line: 3, Column: 1}, "unused variable x", "unused"}
+
+		got, err := NewAnnotationFromLintIssue(&issue)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "::warning file=main.go,line=3,col=1::unused variable x", got.String())
+	})
+
+	t.Run("Flat fields", func(t *testing.T) {
+		issue := struct {
+			Filename string
+			Line     int
+			Column   int
+			Severity string
+			Message  string
+		}{"util.go", 7, 9, "Info", "should omit type"}
+
+		got, err := NewAnnotationFromLintIssue(issue)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "::notice file=util.go,line=7,col=9::should omit type", got.String())
+	})
+
+	t.Run("Missing message", func(t *testing.T) {
+		_, err := NewAnnotationFromLintIssue(struct{ Line int }{1})
+
+		assert.EqualError(t, err, "Lint issue struct { Line int } has no message")
+	})
+
+	t.Run("Unsupported type", func(t *testing.T) {
+		_, err := NewAnnotationFromLintIssue("issue")
+
+		assert.EqualError(t, err, "Unsupported lint issue type string")
+	})
+}
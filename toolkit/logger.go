@@ -0,0 +1,92 @@
+package toolkit
+
+import (
+	"fmt"
+	"io"
+)
+
+// Logger writes annotations to an arbitrary writer. The package-level functions always write to the
+// standard output; a Logger is useful when the toolkit is embedded in a larger tool.
+type Logger struct {
+	out      io.Writer
+	minLevel AnnotationLevel
+	errors   int
+}
+
+// NewLogger creates a new Logger which writes to w. If w is nil, the logger writes to the package's
+// output writer instead, which is the standard output unless changed with SetOutputWriter.
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{out: w}
+}
+
+// SetMinLevel configures the logger to silently drop annotations less severe than level.
+func (l *Logger) SetMinLevel(level AnnotationLevel) {
+	l.minLevel = level
+}
+
+// writer returns the writer the logger writes to.
+func (l *Logger) writer() io.Writer {
+	if l.out == nil {
+		return writer(&out)
+	}
+
+	return l.out
+}
+
+// ErrorCount returns the number of error-level annotations the logger has written so far.
+func (l *Logger) ErrorCount() int {
+	return l.errors
+}
+
+// Annotate writes an Annotation to the logger's writer, unless it is less severe than the logger's
+// minimum level. The annotation is normalised and truncated just like the package-level Annotate
+// does.
+func (l *Logger) Annotate(annotation Annotation) (n int, err error) {
+	if annotationLevels[annotation.level] < l.minLevel {
+		return 0, nil
+	}
+
+	if annotation.level == "error" {
+		l.errors++
+	}
+
+	annotation, dropped := annotation.normalise().truncate()
+
+	n, err = fmt.Fprintln(l.writer(), annotation.String())
+
+	if err == nil && dropped != 0 {
+		l.Debug(fmt.Sprintf("Annotation message exceeded %d bytes, %d bytes were dropped", MaxAnnotationMessageBytes, dropped))
+	}
+
+	return n, err
+}
+
+// AnnotateStringer writes an annotation of the given level with s as its message, positioned at
+// file, line and col. s.String() is only called if the annotation is not dropped due to its level.
+func (l *Logger) AnnotateStringer(level AnnotationLevel, s fmt.Stringer, file string, line, col int) (n int, err error) {
+	if level < l.minLevel {
+		return 0, nil
+	}
+
+	return l.Annotate(NewAnnotation(level, s.String()).SetPosition(file, line, col))
+}
+
+// Error writes an error-level message to the logger's writer.
+func (l *Logger) Error(message string) (n int, err error) {
+	return l.Annotate(NewError(message))
+}
+
+// Warning writes a warning-level message to the logger's writer.
+func (l *Logger) Warning(message string) (n int, err error) {
+	return l.Annotate(NewWarning(message))
+}
+
+// Notice writes a notice-level message to the logger's writer.
+func (l *Logger) Notice(message string) (n int, err error) {
+	return l.Annotate(NewNotice(message))
+}
+
+// Debug writes a debug-level message to the logger's writer.
+func (l *Logger) Debug(message string) (n int, err error) {
+	return l.Annotate(NewDebug(message))
+}
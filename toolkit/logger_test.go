@@ -0,0 +1,105 @@
+package toolkit
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_Logger(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := NewLogger(buffer)
+
+	logger.Debug("hello debug")
+	logger.Notice("hello notice")
+	logger.Warning("hello warning")
+	logger.Error("hello error")
+
+	want := "::debug::hello debug\n::notice::hello notice\n::warning::hello warning\n::error::hello error\n"
+	assert.Equal(t, want, buffer.String())
+}
+
+func Test_Logger_PackageWriter(t *testing.T) {
+	logger := NewLogger(nil)
+
+	got := capture(func() {
+		logger.Warning("hello warning")
+	})
+
+	assert.Equal(t, "::warning::hello warning\n", got)
+}
+
+func Test_LoggerSetMinLevel(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := NewLogger(buffer)
+	logger.SetMinLevel(LevelWarning)
+
+	n, err := logger.Debug("hello debug")
+	logger.Notice("hello notice")
+	logger.Warning("hello warning")
+	logger.Error("hello error")
+
+	assert.Equal(t, 0, n)
+	assert.NoError(t, err)
+	assert.Equal(t, "::warning::hello warning\n::error::hello error\n", buffer.String())
+}
+
+func Test_LoggerAnnotate(t *testing.T) {
+	t.Run("Normalised", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		logger := NewLogger(buffer)
+
+		logger.Annotate(NewError("  indented\n").SetPosition("main.go", -1, 0))
+
+		assert.Equal(t, "::error file=main.go::  indented\n", buffer.String())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		logger := NewLogger(buffer)
+
+		logger.Warning(strings.Repeat("a", MaxAnnotationMessageBytes+1))
+		lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasSuffix(lines[0], truncatedSuffix))
+		assert.Equal(t, fmt.Sprintf("::debug::Annotation message exceeded %d bytes, %d bytes were dropped", MaxAnnotationMessageBytes, len(truncatedSuffix)+1), lines[1])
+	})
+}
+
+func Test_LoggerErrorCount(t *testing.T) {
+	logger := NewLogger(&bytes.Buffer{})
+	logger.SetMinLevel(LevelError)
+
+	logger.Warning("hello warning")
+	logger.Error("hello error")
+	logger.Error("hello again")
+
+	assert.Equal(t, 2, logger.ErrorCount())
+}
+
+func Test_LoggerAnnotateStringer(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := NewLogger(buffer)
+	logger.SetMinLevel(LevelWarning)
+	s := &countingStringer{}
+
+	logger.AnnotateStringer(LevelDebug, s, "", 0, 0)
+	assert.Equal(t, 0, s.calls)
+
+	logger.AnnotateStringer(LevelError, s, "main.go", 1, 2)
+	assert.Equal(t, 1, s.calls)
+	assert.Equal(t, "::error file=main.go,line=1,col=2::hello world\n", buffer.String())
+}
+
+// countingStringer counts how many times its String method was called.
+type countingStringer struct {
+	calls int
+}
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return "hello world"
+}
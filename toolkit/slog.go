@@ -0,0 +1,92 @@
+//go:build go1.21
+// +build go1.21
+
+package toolkit
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogAnnotationKey is the key of the attribute created by AnnotationToSlogAttr.
+const SlogAnnotationKey = "github_annotation"
+
+// AnnotationToSlogAttr converts the annotation into a log/slog attribute, grouping its level,
+// message, file, line, col and title. Records logged with the attribute through a SlogHandler are
+// written as workflow commands.
+func AnnotationToSlogAttr(a Annotation) slog.Attr {
+	return slog.Attr{Key: SlogAnnotationKey, Value: slog.GroupValue(
+		slog.String("level", a.level),
+		slog.String("message", a.message),
+		slog.String("file", a.File),
+		slog.Int("line", a.Line),
+		slog.Int("col", a.Col),
+		slog.String("title", a.Title),
+	)}
+}
+
+// SlogHandler is a slog.Handler which writes the annotations attached to records with
+// AnnotationToSlogAttr as workflow commands before passing the records on to the wrapped handler.
+// The annotation attribute must be passed to the logging call itself, not to Logger.With.
+type SlogHandler struct {
+	slog.Handler
+}
+
+// NewSlogHandler wraps next in a SlogHandler.
+func NewSlogHandler(next slog.Handler) *SlogHandler {
+	return &SlogHandler{Handler: next}
+}
+
+// Handle writes the record's annotation, if any, and passes the record on to the wrapped handler.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+
+	r.Attrs(func(attr slog.Attr) bool {
+		if attr.Key != SlogAnnotationKey || attr.Value.Kind() != slog.KindGroup {
+			return true
+		}
+
+		_, err = Annotate(annotationFromSlog(attr.Value))
+		return false
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a SlogHandler wrapping the wrapped handler's WithAttrs.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SlogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a SlogHandler wrapping the wrapped handler's WithGroup.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// annotationFromSlog converts a group created by AnnotationToSlogAttr back into an annotation.
+func annotationFromSlog(value slog.Value) Annotation {
+	a := Annotation{}
+
+	for _, attr := range value.Group() {
+		switch attr.Key {
+		case "level":
+			a.level = attr.Value.String()
+		case "message":
+			a.message = attr.Value.String()
+		case "file":
+			a.File = attr.Value.String()
+		case "line":
+			a.Line = int(attr.Value.Int64())
+		case "col":
+			a.Col = int(attr.Value.Int64())
+		case "title":
+			a.Title = attr.Value.String()
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,34 @@
+//go:build go1.21
+// +build go1.21
+
+package toolkit
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func Test_AnnotationToSlogAttr(t *testing.T) {
+	a := NewAnnotation(LevelWarning, "unused variable", WithTitle("lint"), WithFile("main.go"), WithLine(3), WithCol(5))
+	got := AnnotationToSlogAttr(a)
+
+	assert.Equal(t, SlogAnnotationKey, got.Key)
+	assert.Equal(t, slog.KindGroup, got.Value.Kind())
+	assert.Equal(t, a, annotationFromSlog(got.Value))
+}
+
+func Test_SlogHandler(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := slog.New(NewSlogHandler(slog.NewTextHandler(buffer, nil))).With("component", "lint")
+
+	got := capture(func() {
+		logger.Warn("found problems", AnnotationToSlogAttr(NewWarning("unused variable").SetPosition("main.go", 3, 5)))
+		logger.Info("done")
+	})
+
+	assert.Equal(t, "::warning file=main.go,line=3,col=5::unused variable\n", got)
+	assert.Contains(t, buffer.String(), "msg=\"found problems\" component=lint github_annotation.level=warning")
+	assert.Contains(t, buffer.String(), "msg=done component=lint\n")
+}
@@ -0,0 +1,205 @@
+package toolkit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// MaxSummarySize is the maximum size of a step summary, in bytes, accepted by GitHub.
+const MaxSummarySize = 1024 * 1024
+
+// Summary builds markdown for the current step's summary in memory.
+type Summary struct {
+	buffer strings.Builder
+}
+
+// NewSummary creates a new, empty Summary.
+func NewSummary() *Summary {
+	return &Summary{}
+}
+
+// AddRaw appends markdown to the summary.
+func (s *Summary) AddRaw(markdown string) {
+	s.buffer.WriteString(markdown)
+}
+
+// allowedSummaryElements are the HTML elements which survive GitHub's sanitisation of markdown.
+var allowedSummaryElements = map[string]bool{
+	"a": true, "b": true, "blockquote": true, "br": true, "code": true, "dd": true, "del": true,
+	"details": true, "div": true, "dl": true, "dt": true, "em": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "i": true, "img": true, "ins": true,
+	"kbd": true, "li": true, "ol": true, "p": true, "pre": true, "span": true, "strong": true,
+	"sub": true, "summary": true, "sup": true, "table": true, "tbody": true, "td": true,
+	"tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+var htmlTagPattern = regexp.MustCompile(`<\s*([a-zA-Z][a-zA-Z0-9-]*)`)
+
+// AddRawHTML appends HTML to the summary. GitHub sanitises the rendered summary, so a warning listing
+// the elements which are likely to be stripped is written if the HTML contains any of them.
+func (s *Summary) AddRawHTML(html string) {
+	stripped := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, match := range htmlTagPattern.FindAllStringSubmatch(html, -1) {
+		tag := strings.ToLower(match[1])
+
+		if !allowedSummaryElements[tag] && !seen[tag] {
+			seen[tag] = true
+			stripped = append(stripped, tag)
+		}
+	}
+
+	if len(stripped) != 0 {
+		Warning(fmt.Sprintf("Step summary HTML elements may be stripped by GitHub: %s", strings.Join(stripped, ", ")))
+	}
+
+	s.buffer.WriteString(html)
+}
+
+// WriteString appends content to the summary and returns the summary for chaining.
+func (s *Summary) WriteString(content string) *Summary {
+	s.buffer.WriteString(content)
+	return s
+}
+
+// Stringify returns the markdown accumulated so far without writing or resetting it.
+func (s *Summary) Stringify() string {
+	return s.buffer.String()
+}
+
+// Write appends the accumulated markdown to the step summary and empties the summary.
+// An error is returned if the runner does not support step summaries.
+func (s *Summary) Write() error {
+	path, err := stepSummaryPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := appendToFile(path, []byte(s.buffer.String())); err != nil {
+		return err
+	}
+
+	s.buffer.Reset()
+
+	return nil
+}
+
+// AppendStepSummary appends markdown to the current step's summary.
+// An error is returned if the runner does not support step summaries.
+func AppendStepSummary(content string) error {
+	path, err := stepSummaryPath()
+	if err != nil {
+		return err
+	}
+
+	_, err = appendToFile(path, []byte(content))
+
+	return err
+}
+
+// WriteStepSummary replaces the current step's summary with markdown.
+// An error is returned if the runner does not support step summaries.
+func WriteStepSummary(content string) error {
+	path, err := stepSummaryPath()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, []byte(content), 0644)
+}
+
+// WriteStepSummaryWithTitle replaces the current step's summary with a level one heading followed
+// by markdown.
+func WriteStepSummaryWithTitle(title string, content string) error {
+	return WriteStepSummary(fmt.Sprintf("# %s\n\n%s", title, content))
+}
+
+// SummaryWriter streams markdown into the current step's summary. Writes are buffered in memory and
+// appended to the file referenced by GITHUB_STEP_SUMMARY whenever a full line is available, or when
+// Flush is called.
+type SummaryWriter struct {
+	path    string
+	buffer  bytes.Buffer
+	written int
+}
+
+// NewSummaryWriter creates a SummaryWriter for the current step's summary.
+// An error is returned if the runner does not support step summaries.
+func NewSummaryWriter() (*SummaryWriter, error) {
+	path, err := stepSummaryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SummaryWriter{path: path}, nil
+}
+
+// Write buffers p and appends all complete lines to the step summary. io.ErrShortWrite is returned
+// without writing anything if p would make the summary exceed MaxSummarySize. If appending to the
+// summary fails, the number of bytes of p which made it into the file is returned with the error and
+// the rest of p is discarded, so that it can be written again. Data buffered by earlier calls is kept.
+func (w *SummaryWriter) Write(p []byte) (n int, err error) {
+	if w.written+len(p) > MaxSummarySize {
+		return 0, io.ErrShortWrite
+	}
+
+	pending := w.buffer.Len()
+	w.buffer.Write(p)
+
+	if i := bytes.LastIndexByte(w.buffer.Bytes(), '\n'); i != -1 {
+		var flushed int
+
+		if flushed, err = w.flush(i + 1); err != nil {
+			if n = flushed - pending; n < 0 {
+				n = 0
+			}
+
+			// The unwritten part of p is at the end of the buffer
+			w.buffer.Truncate(w.buffer.Len() - (len(p) - n))
+			w.written += n
+
+			return n, err
+		}
+	}
+
+	w.written += len(p)
+
+	return len(p), nil
+}
+
+// Flush appends everything buffered so far to the step summary, including incomplete lines. Data
+// which could not be appended stays buffered, so that Flush can be retried.
+func (w *SummaryWriter) Flush() error {
+	_, err := w.flush(w.buffer.Len())
+	return err
+}
+
+// flush appends the first n buffered bytes to the step summary. Only the bytes which were actually
+// written are removed from the buffer, and their number is returned.
+func (w *SummaryWriter) flush(n int) (int, error) {
+	if n == 0 {
+		return 0, nil
+	}
+
+	written, err := appendToFile(w.path, w.buffer.Bytes()[:n])
+	w.buffer.Next(written)
+
+	return written, err
+}
+
+// stepSummaryPath returns the path of the current step's summary file.
+func stepSummaryPath() (string, error) {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+
+	if len(path) == 0 {
+		return "", fmt.Errorf("Step summary not available, GITHUB_STEP_SUMMARY is not set")
+	}
+
+	return path, nil
+}
@@ -0,0 +1,193 @@
+package toolkit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Summary(t *testing.T) {
+	t.Run("Stringify", func(t *testing.T) {
+		summary := NewSummary()
+		summary.AddRaw("# Results\n")
+		summary.AddRaw("All tests passed\n")
+
+		assert.Equal(t, "# Results\nAll tests passed\n", summary.Stringify())
+		assert.Equal(t, "# Results\nAll tests passed\n", summary.Stringify())
+	})
+
+	t.Run("AddRawHTML", func(t *testing.T) {
+		summary := NewSummary()
+		got := capture(func() {
+			summary.AddRawHTML("<details><summary>Logs</summary>output</details>")
+		})
+
+		assert.Empty(t, got)
+		assert.Equal(t, "<details><summary>Logs</summary>output</details>", summary.Stringify())
+	})
+
+	t.Run("AddRawHTML stripped elements", func(t *testing.T) {
+		summary := NewSummary()
+		got := capture(func() {
+			summary.AddRawHTML("<style>p {}</style><p>text</p><SCRIPT>x</SCRIPT><style></style>")
+		})
+
+		assert.Equal(t, "::warning::Step summary HTML elements may be stripped by GitHub: style, script\n", got)
+		assert.Equal(t, "<style>p {}</style><p>text</p><SCRIPT>x</SCRIPT><style></style>", summary.Stringify())
+	})
+
+	t.Run("WriteString", func(t *testing.T) {
+		summary := NewSummary().WriteString("# Results\n").WriteString("All tests passed\n")
+
+		assert.Equal(t, "# Results\nAll tests passed\n", summary.Stringify())
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		path, restore := withSummary(t)
+		defer restore()
+
+		summary := NewSummary()
+		summary.AddRaw("# Results\n")
+		summary.Stringify()
+
+		assert.NoError(t, summary.Write())
+		assert.Equal(t, "# Results\n", readFile(t, path))
+		assert.Empty(t, summary.Stringify())
+	})
+}
+
+func Test_AppendStepSummary(t *testing.T) {
+	path, restore := withSummary(t)
+	defer restore()
+
+	assert.NoError(t, AppendStepSummary("# Results\n"))
+	assert.NoError(t, AppendStepSummary("All tests passed\n"))
+	assert.Equal(t, "# Results\nAll tests passed\n", readFile(t, path))
+}
+
+func Test_WriteStepSummary(t *testing.T) {
+	t.Run("Overwrite", func(t *testing.T) {
+		path, restore := withSummary(t)
+		defer restore()
+
+		assert.NoError(t, AppendStepSummary("stale\n"))
+		assert.NoError(t, WriteStepSummary("All tests passed\n"))
+		assert.Equal(t, "All tests passed\n", readFile(t, path))
+	})
+
+	t.Run("WithTitle", func(t *testing.T) {
+		path, restore := withSummary(t)
+		defer restore()
+
+		assert.NoError(t, AppendStepSummary("stale\n"))
+		assert.NoError(t, WriteStepSummaryWithTitle("Results", "All tests passed\n"))
+		assert.Equal(t, "# Results\n\nAll tests passed\n", readFile(t, path))
+	})
+
+	t.Run("Summary not available", func(t *testing.T) {
+		original := os.Getenv("GITHUB_STEP_SUMMARY")
+		os.Unsetenv("GITHUB_STEP_SUMMARY")
+		defer os.Setenv("GITHUB_STEP_SUMMARY", original)
+
+		assert.EqualError(t, WriteStepSummary("content"), "Step summary not available, GITHUB_STEP_SUMMARY is not set")
+	})
+}
+
+func Test_NewSummaryWriter(t *testing.T) {
+	t.Run("Summary not available", func(t *testing.T) {
+		original := os.Getenv("GITHUB_STEP_SUMMARY")
+		os.Unsetenv("GITHUB_STEP_SUMMARY")
+		defer os.Setenv("GITHUB_STEP_SUMMARY", original)
+
+		_, err := NewSummaryWriter()
+
+		assert.EqualError(t, err, "Step summary not available, GITHUB_STEP_SUMMARY is not set")
+	})
+}
+
+func Test_SummaryWriter(t *testing.T) {
+	t.Run("Flushes complete lines", func(t *testing.T) {
+		path, restore := withSummary(t)
+		defer restore()
+
+		w, _ := NewSummaryWriter()
+		w.Write([]byte("# Results\nincomplete"))
+
+		assert.Equal(t, "# Results\n", readFile(t, path))
+
+		w.Flush()
+
+		assert.Equal(t, "# Results\nincomplete", readFile(t, path))
+	})
+
+	t.Run("Failed append", func(t *testing.T) {
+		path, restore := withSummary(t)
+		defer restore()
+
+		w, _ := NewSummaryWriter()
+		w.Write([]byte("partial"))
+		w.path = filepath.Join(path, "not-a-directory")
+
+		n, err := w.Write([]byte(" line\n"))
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, n)
+		assert.Error(t, w.Flush())
+
+		w.path = path
+		n, err = w.Write([]byte(" line\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, n)
+		assert.Equal(t, "partial line\n", readFile(t, path))
+	})
+
+	t.Run("Size limit", func(t *testing.T) {
+		_, restore := withSummary(t)
+		defer restore()
+
+		w, _ := NewSummaryWriter()
+		n, err := w.Write([]byte(strings.Repeat("a", MaxSummarySize)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, MaxSummarySize, n)
+
+		n, err = w.Write([]byte("a"))
+
+		assert.Equal(t, io.ErrShortWrite, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+// withSummary points GITHUB_STEP_SUMMARY at an empty temporary file. The returned function restores
+// the original environment.
+func withSummary(t *testing.T) (string, func()) {
+	file, err := ioutil.TempFile("", "summary-*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.Close()
+
+	original := os.Getenv("GITHUB_STEP_SUMMARY")
+	os.Setenv("GITHUB_STEP_SUMMARY", file.Name())
+
+	return file.Name(), func() {
+		os.Setenv("GITHUB_STEP_SUMMARY", original)
+		os.Remove(file.Name())
+	}
+}
+
+// readFile returns the contents of the file at path.
+func readFile(t *testing.T, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(contents)
+}
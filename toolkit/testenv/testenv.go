@@ -0,0 +1,51 @@
+// Package testenv implements helpers which set environment variables for the duration of a test,
+// like testing.T.Setenv does on Go 1.17 and later. The helpers only need testing.TB.Cleanup and thus
+// work on Go 1.14 and later.
+package testenv
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertrossmann/actions/toolkit"
+)
+
+// Setenv sets an environment variable and restores its original value, or unsets it, when the test
+// finishes.
+func Setenv(t testing.TB, key string, value string) {
+	original, present := os.LookupEnv(key)
+
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if present {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// SetInput sets the value of an action input for the duration of the test, using the environment
+// variable toolkit.GetInput reads it from.
+func SetInput(t testing.TB, name string, value string) {
+	Setenv(t, "INPUT_"+strings.ReplaceAll(strings.ToUpper(name), " ", "_"), value)
+}
+
+// SetMetadata sets the environment variable which the named field of toolkit.Metadata is read from
+// for the duration of the test, ie. SetMetadata(t, "Ref", "refs/heads/main") sets GITHUB_REF. The
+// test fails if there is no such field.
+func SetMetadata(t testing.TB, field string, value string) {
+	meta, _ := reflect.TypeOf((*toolkit.Metadata)(nil)).Elem().FieldByName(field)
+	key, ok := meta.Tag.Lookup("env")
+	if !ok {
+		t.Fatalf("Metadata has no field %s", field)
+		return
+	}
+
+	Setenv(t, key, value)
+}
@@ -0,0 +1,68 @@
+package testenv
+
+import (
+	"fmt"
+	"github.com/robertrossmann/actions/toolkit"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_Setenv(t *testing.T) {
+	os.Unsetenv("TEST_ENV_VAR")
+
+	t.Run("Set", func(t *testing.T) {
+		Setenv(t, "TEST_ENV_VAR", "testvalue")
+
+		assert.Equal(t, "testvalue", os.Getenv("TEST_ENV_VAR"))
+	})
+
+	_, present := os.LookupEnv("TEST_ENV_VAR")
+	assert.False(t, present)
+}
+
+func Test_SetInput(t *testing.T) {
+	SetInput(t, "log level", "debug")
+
+	got, err := toolkit.GetInput("log level")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", got)
+}
+
+func Test_SetMetadata(t *testing.T) {
+	t.Run("All fields", func(t *testing.T) {
+		meta := reflect.TypeOf(toolkit.Metadata{})
+
+		for i := 0; i < meta.NumField(); i++ {
+			field := meta.Field(i)
+
+			if len(field.PkgPath) != 0 || field.Name == "Token" {
+				continue
+			}
+
+			SetMetadata(t, field.Name, "value of "+field.Name)
+			got := reflect.ValueOf(toolkit.GetMetadata()).Elem().Field(i).String()
+
+			assert.Equal(t, "value of "+field.Name, got)
+		}
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		SetMetadata(tb, "Unknown", "value")
+
+		assert.Equal(t, "Metadata has no field Unknown", tb.fatal)
+	})
+}
+
+// fakeTB records the message passed to Fatalf instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	fatal string
+}
+
+func (tb *fakeTB) Fatalf(format string, args ...interface{}) {
+	tb.fatal = fmt.Sprintf(format, args...)
+}
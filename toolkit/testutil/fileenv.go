@@ -0,0 +1,55 @@
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robertrossmann/actions/toolkit/testenv"
+)
+
+// FileEnv holds the paths of the temporary files created by NewFileEnv.
+type FileEnv struct {
+	Env    string
+	Output string
+	Path   string
+	State  string
+}
+
+// NewFileEnv creates empty temporary files for GITHUB_ENV, GITHUB_OUTPUT, GITHUB_PATH and
+// GITHUB_STATE and points the environment variables at them, so that tests do not touch the real
+// runner's files. The files are removed and the environment is restored when the test finishes.
+func NewFileEnv(t testing.TB) *FileEnv {
+	dir, err := ioutil.TempDir("", "toolkit-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	env := &FileEnv{
+		Env:    filepath.Join(dir, "env"),
+		Output: filepath.Join(dir, "output"),
+		Path:   filepath.Join(dir, "path"),
+		State:  filepath.Join(dir, "state"),
+	}
+	vars := map[string]string{
+		"GITHUB_ENV":    env.Env,
+		"GITHUB_OUTPUT": env.Output,
+		"GITHUB_PATH":   env.Path,
+		"GITHUB_STATE":  env.State,
+	}
+
+	for key, path := range vars {
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		testenv.Setenv(t, key, path)
+	}
+
+	return env
+}
@@ -0,0 +1,27 @@
+package testutil
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func Test_NewFileEnv(t *testing.T) {
+	os.Setenv("GITHUB_OUTPUT", "/original/output")
+	defer os.Unsetenv("GITHUB_OUTPUT")
+
+	tb := &fakeTB{}
+	env := NewFileEnv(tb)
+
+	assert.Equal(t, env.Env, os.Getenv("GITHUB_ENV"))
+	assert.Equal(t, env.Output, os.Getenv("GITHUB_OUTPUT"))
+	assert.Equal(t, env.Path, os.Getenv("GITHUB_PATH"))
+	assert.Equal(t, env.State, os.Getenv("GITHUB_STATE"))
+	assert.FileExists(t, env.Output)
+
+	tb.cleanup()
+
+	assert.Equal(t, "/original/output", os.Getenv("GITHUB_OUTPUT"))
+	_, err := os.Stat(env.Output)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,45 @@
+package testutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/robertrossmann/actions/toolkit"
+)
+
+// TestLogger is a toolkit.Logger created by NewTestLogger.
+type TestLogger struct {
+	*toolkit.Logger
+	allowErrors bool
+}
+
+// NewTestLogger creates a Logger which writes each line through t.Log, so that the output is
+// associated with the correct test case. When the test finishes, the test fails if any error-level
+// annotations were written, unless AllowErrors was called on the logger.
+func NewTestLogger(t testing.TB) *TestLogger {
+	l := &TestLogger{Logger: toolkit.NewLogger(testWriter{t})}
+
+	t.Cleanup(func() {
+		if n := l.ErrorCount(); n > 0 && !l.allowErrors {
+			t.Errorf("Logger emitted %d error annotation(s)", n)
+		}
+	})
+
+	return l
+}
+
+// AllowErrors permits error-level annotations to be written to the logger without failing the test.
+func (l *TestLogger) AllowErrors() *TestLogger {
+	l.allowErrors = true
+	return l
+}
+
+// testWriter forwards everything written to it to t.Log.
+type testWriter struct {
+	t testing.TB
+}
+
+func (w testWriter) Write(p []byte) (n int, err error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
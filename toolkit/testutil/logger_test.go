@@ -0,0 +1,66 @@
+package testutil
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NewTestLogger(t *testing.T) {
+	t.Run("Logs through t.Log", func(t *testing.T) {
+		tb := &fakeTB{}
+		logger := NewTestLogger(tb)
+
+		logger.Warning("hello world")
+		tb.cleanup()
+
+		assert.Equal(t, []string{"::warning::hello world"}, tb.logs)
+		assert.Empty(t, tb.errors)
+	})
+
+	t.Run("Fails on errors", func(t *testing.T) {
+		tb := &fakeTB{}
+		logger := NewTestLogger(tb)
+
+		logger.Error("hello world")
+		tb.cleanup()
+
+		assert.Equal(t, []string{"Logger emitted 1 error annotation(s)"}, tb.errors)
+	})
+
+	t.Run("AllowErrors", func(t *testing.T) {
+		tb := &fakeTB{}
+		logger := NewTestLogger(tb).AllowErrors()
+
+		logger.Error("hello world")
+		tb.cleanup()
+
+		assert.Empty(t, tb.errors)
+	})
+}
+
+// fakeTB records calls made by the helpers under test without failing the real test.
+type fakeTB struct {
+	testing.TB
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Log(args ...interface{}) {
+	tb.logs = append(tb.logs, fmt.Sprint(args...))
+}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+func (tb *fakeTB) cleanup() {
+	for _, f := range tb.cleanups {
+		f()
+	}
+}
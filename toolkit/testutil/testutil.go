@@ -0,0 +1,141 @@
+// Package testutil provides a fake GitHub Actions runner environment for integration tests of
+// actions built with the toolkit.
+package testutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/robertrossmann/actions/toolkit"
+	"github.com/robertrossmann/actions/toolkit/testenv"
+)
+
+// FakeRunner is a fake runner environment created by NewFakeRunner.
+type FakeRunner struct {
+	*FileEnv
+
+	// Event is the path of the event payload, an empty push event by default.
+	Event string
+	// Summary is the path of the step summary.
+	Summary string
+	// Workspace is the path of the workspace directory.
+	Workspace string
+
+	output *buffer
+}
+
+// NewFakeRunner points all GITHUB_* environment variables at fake values describing a push to the
+// main branch of octocat/hello-world, with temporary files for the event payload, step summary and
+// all file commands. Everything written to the toolkit's output writer is captured. The outputs set
+// so far are forgotten, so each runner starts without any. The environment, including PATH, and the
+// output writer are restored and the outputs are forgotten again when the test finishes.
+func NewFakeRunner(t testing.TB) *FakeRunner {
+	dir, err := ioutil.TempDir("", "runner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	runner := &FakeRunner{
+		FileEnv:   NewFileEnv(t),
+		Event:     filepath.Join(dir, "event.json"),
+		Summary:   filepath.Join(dir, "summary.md"),
+		Workspace: filepath.Join(dir, "workspace"),
+		output:    &buffer{},
+	}
+
+	if err := ioutil.WriteFile(runner.Event, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(runner.Summary, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(runner.Workspace, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]string{
+		"CI":                         "true",
+		"GITHUB_ACTION":              "__run",
+		"GITHUB_ACTIONS":             "true",
+		"GITHUB_ACTOR":               "octocat",
+		"GITHUB_API_URL":             "https://api.github.com",
+		"GITHUB_EVENT_NAME":          "push",
+		"GITHUB_EVENT_PATH":          runner.Event,
+		"GITHUB_JOB":                 "test",
+		"GITHUB_REF":                 "refs/heads/main",
+		"GITHUB_REF_NAME":            "main",
+		"GITHUB_REF_TYPE":            "branch",
+		"GITHUB_REPOSITORY":          "octocat/hello-world",
+		"GITHUB_REPOSITORY_ID":       "1296269",
+		"GITHUB_REPOSITORY_OWNER":    "octocat",
+		"GITHUB_REPOSITORY_OWNER_ID": "583231",
+		"GITHUB_RUN_ID":              "1",
+		"GITHUB_RUN_NUMBER":          "1",
+		"GITHUB_SERVER_URL":          "https://github.com",
+		"GITHUB_SHA":                 "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		"GITHUB_STEP_SUMMARY":        runner.Summary,
+		"GITHUB_WORKFLOW":            "CI",
+		"GITHUB_WORKFLOW_REF":        "octocat/hello-world/.github/workflows/ci.yml@refs/heads/main",
+		"GITHUB_WORKSPACE":           runner.Workspace,
+		"RUNNER_OS":                  "Linux",
+		"RUNNER_TEMP":                dir,
+	}
+
+	for key, value := range vars {
+		testenv.Setenv(t, key, value)
+	}
+
+	// PrependPath modifies PATH of the current process as well
+	testenv.Setenv(t, "PATH", os.Getenv("PATH"))
+
+	// Values of the real runner, if any, must not leak into the fake environment
+	for _, key := range []string{"GITHUB_BASE_REF", "GITHUB_HEAD_REF", "GITHUB_TOKEN", "RUNNER_DEBUG"} {
+		testenv.Setenv(t, key, "")
+		os.Unsetenv(key)
+	}
+
+	toolkit.SetOutputWriter(runner.output)
+	toolkit.ResetOutputs()
+
+	t.Cleanup(func() {
+		toolkit.SetOutputWriter(nil)
+		toolkit.ResetOutputs()
+	})
+
+	return runner
+}
+
+// CapturedOutput returns everything written to the toolkit's output writer so far.
+func (r *FakeRunner) CapturedOutput() string {
+	return r.output.String()
+}
+
+// buffer is a bytes.Buffer which is safe for concurrent use.
+type buffer struct {
+	sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+func (b *buffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.buffer.String()
+}
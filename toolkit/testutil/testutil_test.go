@@ -0,0 +1,96 @@
+package testutil
+
+import (
+	"github.com/robertrossmann/actions/toolkit"
+	"github.com/robertrossmann/actions/toolkit/cmd"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func Test_NewFakeRunner(t *testing.T) {
+	t.Run("Environment", func(t *testing.T) {
+		runner := NewFakeRunner(t)
+		meta := toolkit.GetMetadata()
+
+		assert.Equal(t, "octocat/hello-world", meta.Repository)
+		assert.Equal(t, "main", mustBranch(t, meta))
+		assert.Equal(t, runner.Event, meta.EventPath)
+		assert.Equal(t, runner.Workspace, meta.Workspace)
+		assert.Empty(t, meta.Token)
+	})
+
+	t.Run("CapturedOutput", func(t *testing.T) {
+		runner := NewFakeRunner(t)
+
+		toolkit.Warning("careful")
+		toolkit.Debug("hello world")
+
+		assert.Equal(t, "::warning::careful\n::debug::hello world\n", runner.CapturedOutput())
+	})
+
+	t.Run("CapturedOutput of cmd.Run", func(t *testing.T) {
+		runner := NewFakeRunner(t)
+
+		cmd.Run(func(log *toolkit.Logger) int {
+			log.Warning("careful")
+			return 0
+		})
+
+		assert.Equal(t, "::warning::careful\n", runner.CapturedOutput())
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		runner := NewFakeRunner(t)
+
+		_, err := toolkit.PrependPath("/usr/dummy/bin")
+		assert.NoError(t, err)
+		assert.NoError(t, toolkit.AppendStepSummary("# Results\n"))
+
+		assert.Equal(t, "/usr/dummy/bin\n", read(t, runner.Path))
+		assert.Equal(t, "# Results\n", read(t, runner.Summary))
+	})
+
+	t.Run("Outputs", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			t.Run("Fake runner", func(t *testing.T) {
+				runner := NewFakeRunner(t)
+
+				_, err := toolkit.SetOutputStrict("result", "success")
+
+				assert.NoError(t, err)
+				assert.Empty(t, runner.CapturedOutput())
+				assert.Equal(t, "result<<ghadelimiter\nsuccess\nghadelimiter\n", read(t, runner.Output))
+			})
+		}
+	})
+
+	t.Run("Restores the environment", func(t *testing.T) {
+		original := os.Getenv("GITHUB_REPOSITORY")
+
+		t.Run("Fake runner", func(t *testing.T) {
+			NewFakeRunner(t)
+		})
+
+		assert.Equal(t, original, os.Getenv("GITHUB_REPOSITORY"))
+	})
+}
+
+func mustBranch(t *testing.T, meta *toolkit.Metadata) string {
+	branch, err := meta.GetBranchName()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return branch
+}
+
+func read(t *testing.T, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(contents)
+}
@@ -4,68 +4,452 @@
 package toolkit
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
 )
 
+// writers guards the package's writers, which can be replaced at runtime.
+var writers sync.RWMutex
+
 var out io.Writer = os.Stdout
 
+// errOut receives error-level and notice-level annotations instead of out when set.
+var errOut io.Writer
+
+// envOut receives environment variable commands instead of out when set.
+var envOut io.Writer
+
 func println(message string) (n int, err error) {
-	return fmt.Fprintln(out, message)
+	return fmt.Fprintln(writer(&out), message)
+}
+
+// writer safely reads one of the package's writers.
+func writer(w *io.Writer) io.Writer {
+	writers.RLock()
+	defer writers.RUnlock()
+
+	return *w
+}
+
+// setWriter safely replaces one of the package's writers.
+func setWriter(w *io.Writer, value io.Writer) {
+	writers.Lock()
+	defer writers.Unlock()
+
+	*w = value
+}
+
+// SetOutputWriter sets the writer all workflow commands are written to, which is the standard output
+// by default. This is useful when the toolkit is used as a library inside a larger tool. Passing nil
+// restores the default. It is safe to call concurrently with other functions of this package.
+func SetOutputWriter(w io.Writer) {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	setWriter(&out, w)
+}
+
+// SetEnvWriter routes the commands written by Setenv to w instead of the output writer. It has no
+// effect while the runner provides a GITHUB_ENV file, which Setenv writes to instead. Passing nil
+// restores the default behaviour. It is safe to call concurrently with other functions of this
+// package.
+func SetEnvWriter(w io.Writer) {
+	setWriter(&envOut, w)
+}
+
+// appendToFile appends data to the file at path, creating the file if it does not exist.
+func appendToFile(path string, data []byte) (n int, err error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err = file.Write(data)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return n, err
 }
 
 // Metadata shows information about current action's environment, runtime & event which triggered the workflow.
+//
+// Each field is read from the environment variable named in its env tag.
+//
+// Token holds the value of GITHUB_TOKEN, which is only available if the workflow passes it to the
+// action explicitly. GetMetadata automatically registers it with SetSecret the first time it reads
+// it, so that it is masked in the logs, should it ever be printed by accident.
 type Metadata struct {
-	Action     string
-	Actor      string
-	BaseRef    string
-	EventName  string
-	EventPath  string
-	HeadRef    string
-	Ref        string
-	Repository string
-	RunnerOS   string
-	Sha        string
-	Workflow   string
-	Workspace  string
+	Action            string `env:"GITHUB_ACTION"`
+	ActionPath        string `env:"GITHUB_ACTION_PATH"`
+	ActionRef         string `env:"GITHUB_ACTION_REF"`
+	ActionRepository  string `env:"GITHUB_ACTION_REPOSITORY"`
+	Actor             string `env:"GITHUB_ACTOR"`
+	APIURL            string `env:"GITHUB_API_URL"`
+	BaseRef           string `env:"GITHUB_BASE_REF"`
+	Environment       string `env:"GITHUB_ENVIRONMENT"`
+	EnvironmentURL    string `env:"GITHUB_ENVIRONMENT_URL"`
+	EventName         string `env:"GITHUB_EVENT_NAME"`
+	EventPath         string `env:"GITHUB_EVENT_PATH"`
+	HeadRef           string `env:"GITHUB_HEAD_REF"`
+	InternalJobID     string `env:"GITHUB_INTERNAL_JOB_ID"`
+	Ref               string `env:"GITHUB_REF"`
+	RefType           string `env:"GITHUB_REF_TYPE"`
+	Repository        string `env:"GITHUB_REPOSITORY"`
+	RepositoryID      string `env:"GITHUB_REPOSITORY_ID"`
+	RepositoryOwner   string `env:"GITHUB_REPOSITORY_OWNER"`
+	RepositoryOwnerID string `env:"GITHUB_REPOSITORY_OWNER_ID"`
+	RunnerOS          string `env:"RUNNER_OS"`
+	ServerURL         string `env:"GITHUB_SERVER_URL"`
+	Sha               string `env:"GITHUB_SHA"`
+	Token             string `env:"GITHUB_TOKEN"`
+	Workflow          string `env:"GITHUB_WORKFLOW"`
+	WorkflowRef       string `env:"GITHUB_WORKFLOW_REF"`
+	Workspace         string `env:"GITHUB_WORKSPACE"`
+
+	event *eventCache
 }
 
 // GetMetadata retrieves the current action run's metadata.
 func GetMetadata() *Metadata {
-	meta := &Metadata{}
-	meta.Action = os.Getenv("GITHUB_ACTION")
-	meta.Actor = os.Getenv("GITHUB_ACTOR")
-	meta.BaseRef = os.Getenv("GITHUB_BASE_REF")
-	meta.EventName = os.Getenv("GITHUB_EVENT_NAME")
-	meta.EventPath = os.Getenv("GITHUB_EVENT_PATH")
-	meta.HeadRef = os.Getenv("GITHUB_HEAD_REF")
-	meta.Ref = os.Getenv("GITHUB_REF")
-	meta.Repository = os.Getenv("GITHUB_REPOSITORY")
-	meta.RunnerOS = os.Getenv("RUNNER_OS")
-	meta.Sha = os.Getenv("GITHUB_SHA")
-	meta.Workflow = os.Getenv("GITHUB_WORKFLOW")
-	meta.Workspace = os.Getenv("GITHUB_WORKSPACE")
-
-	return meta
+	return (&Metadata{}).Reload()
+}
+
+// Reload refreshes the metadata in place from the current environment and returns it, so that
+// existing references observe environment changes made since the metadata was retrieved. The cached
+// event payload is discarded as well.
+func (m *Metadata) Reload() *Metadata {
+	meta := reflect.ValueOf(m).Elem()
+
+	for i := 0; i < meta.NumField(); i++ {
+		if key, ok := meta.Type().Field(i).Tag.Lookup("env"); ok {
+			meta.Field(i).SetString(os.Getenv(key))
+		}
+	}
+
+	m.event = &eventCache{}
+
+	if len(m.Token) != 0 {
+		maskToken(m.Token)
+	}
+
+	return m
+}
+
+// maskedToken holds the token most recently registered with SetSecret by maskToken.
+var maskedToken struct {
+	sync.Mutex
+	value string
+}
+
+// maskToken registers the token with SetSecret, unless it has been registered already, so that
+// reading the metadata repeatedly does not write the same add-mask command over and over.
+func maskToken(token string) {
+	maskedToken.Lock()
+	defer maskedToken.Unlock()
+
+	if maskedToken.value == token {
+		return
+	}
+
+	if _, err := SetSecret(token); err == nil {
+		maskedToken.value = token
+	}
+}
+
+// PrintMetadata writes all non-empty fields of the current action run's metadata as debug messages
+// inside an output group. This is useful when troubleshooting the runner's environment. The Token
+// field is never printed.
+func PrintMetadata() error {
+	meta := reflect.ValueOf(GetMetadata()).Elem()
+
+	return WithGroup("GitHub Actions Metadata", func() error {
+		for i := 0; i < meta.NumField(); i++ {
+			if len(meta.Type().Field(i).PkgPath) != 0 {
+				continue
+			}
+
+			value := fmt.Sprint(meta.Field(i).Interface())
+
+			if len(value) == 0 || meta.Type().Field(i).Name == "Token" {
+				continue
+			}
+
+			if _, err := Debug(meta.Type().Field(i).Name + ": " + value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ShortSHA returns the first n characters of the commit SHA which triggered the workflow.
+// n is clamped to the length of the SHA; when n is 0 the conventional length of 7 is used.
+func (m *Metadata) ShortSHA(n int) string {
+	if n == 0 {
+		n = 7
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	if n > len(m.Sha) {
+		n = len(m.Sha)
+	}
+
+	return m.Sha[:n]
+}
+
+// OwnerName returns the owner part of the repository name, ie. octocat for octocat/hello-world.
+// An empty string is returned if the repository name is empty or malformed.
+func (m *Metadata) OwnerName() string {
+	owner, _ := m.splitRepository()
+	return owner
+}
+
+// RepoName returns the name part of the repository name, ie. hello-world for octocat/hello-world.
+// An empty string is returned if the repository name is empty or malformed.
+func (m *Metadata) RepoName() string {
+	_, name := m.splitRepository()
+	return name
+}
+
+// RefWithoutPrefix returns the branch or tag name from Ref without its refs/heads/ or refs/tags/
+// prefix. Other refs are returned unchanged.
+func (m *Metadata) RefWithoutPrefix() string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if strings.HasPrefix(m.Ref, prefix) {
+			return strings.TrimPrefix(m.Ref, prefix)
+		}
+	}
+
+	return m.Ref
+}
+
+// IsTag reports whether the workflow was triggered by a tag. Runners which do not report RefType
+// are supported by inspecting the Ref prefix instead.
+func (m *Metadata) IsTag() bool {
+	if len(m.RefType) != 0 {
+		return m.RefType == "tag"
+	}
+
+	return strings.HasPrefix(m.Ref, "refs/tags/")
+}
+
+// IsBranch reports whether the workflow was triggered by a branch. Runners which do not report
+// RefType are supported by inspecting the Ref prefix instead.
+func (m *Metadata) IsBranch() bool {
+	if len(m.RefType) != 0 {
+		return m.RefType == "branch"
+	}
+
+	return strings.HasPrefix(m.Ref, "refs/heads/")
+}
+
+// GetTagName returns the name of the tag which triggered the workflow, ie. v1.2.3 for
+// refs/tags/v1.2.3. An error is returned if the workflow was not triggered by a tag.
+func (m *Metadata) GetTagName() (string, error) {
+	if !m.IsTag() {
+		return "", fmt.Errorf("Ref %s is not a tag", m.Ref)
+	}
+
+	return strings.TrimPrefix(m.Ref, "refs/tags/"), nil
+}
+
+// GetBranchName returns the name of the branch which triggered the workflow, ie. main for
+// refs/heads/main. For pull request events the pull request's head branch is returned instead.
+// An error is returned if the workflow was not triggered by a branch.
+func (m *Metadata) GetBranchName() (string, error) {
+	if m.isPullRequest() && len(m.HeadRef) != 0 {
+		return m.HeadRef, nil
+	}
+
+	if !m.IsBranch() {
+		return "", fmt.Errorf("Ref %s is not a branch", m.Ref)
+	}
+
+	return strings.TrimPrefix(m.Ref, "refs/heads/"), nil
+}
+
+// PullRequestBaseRef returns the short name of the pull request's base branch, ie. main.
+// An error is returned if the current event is not related to a pull request.
+func (m *Metadata) PullRequestBaseRef() (string, error) {
+	if !m.isPullRequest() {
+		return "", fmt.Errorf("Event %s is not a pull request event", m.EventName)
+	}
+
+	return m.BaseRef, nil
+}
+
+// PullRequestHeadRef returns the short name of the pull request's head branch, ie. feature.
+// An error is returned if the current event is not related to a pull request.
+func (m *Metadata) PullRequestHeadRef() (string, error) {
+	if !m.isPullRequest() {
+		return "", fmt.Errorf("Event %s is not a pull request event", m.EventName)
+	}
+
+	return m.HeadRef, nil
+}
+
+// GetEnvironmentURL returns the URL of the deployment environment the job runs in.
+// An error is returned if the job does not deploy to an environment with a URL.
+func (m *Metadata) GetEnvironmentURL() (string, error) {
+	if len(m.EnvironmentURL) == 0 {
+		return "", fmt.Errorf("Deployment environment URL not available")
+	}
+
+	return m.EnvironmentURL, nil
+}
+
+// WorkflowURL returns the URL of the workflow file on GitHub at the commit which triggered the
+// workflow. The workflow file is taken from WorkflowRef, falling back to Workflow on runners which do
+// not report it.
+func (m *Metadata) WorkflowURL() string {
+	server := m.ServerURL
+
+	if len(server) == 0 {
+		server = "https://github.com"
+	}
+
+	// WorkflowRef has the format owner/repo/.github/workflows/ci.yml@refs/heads/main
+	path := strings.TrimPrefix(strings.SplitN(m.WorkflowRef, "@", 2)[0], m.Repository+"/")
+
+	if len(m.WorkflowRef) == 0 {
+		path = m.Workflow
+
+		if !strings.HasPrefix(path, ".github/workflows/") {
+			path = ".github/workflows/" + path
+		}
+	}
+
+	return fmt.Sprintf("%s/%s/blob/%s/%s", server, m.Repository, m.Sha, path)
+}
+
+// WorkspaceRelPath returns absPath relative to the workspace, eg. to position annotations reported by
+// tools which print absolute paths. An error is returned if the workspace is not known or absPath is
+// outside of it.
+func (m *Metadata) WorkspaceRelPath(absPath string) (string, error) {
+	if len(m.Workspace) == 0 {
+		return "", fmt.Errorf("Workspace not available, GITHUB_WORKSPACE is not set")
+	}
+
+	rel, err := filepath.Rel(m.Workspace, absPath)
+	if err != nil {
+		return "", err
+	}
+
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Path %s is not inside the workspace %s", absPath, m.Workspace)
+	}
+
+	return rel, nil
+}
+
+// IsReusableWorkflow reports whether the action was loaded from another repository than the one the
+// workflow runs in, ie. when it is called from a reusable workflow or another action.
+func (m *Metadata) IsReusableWorkflow() bool {
+	return len(m.ActionRef) != 0 && len(m.ActionRepository) != 0 && m.ActionRepository != m.Repository
+}
+
+func (m *Metadata) isPullRequest() bool {
+	return m.EventName == "pull_request" || m.EventName == "pull_request_target"
+}
+
+func (m *Metadata) splitRepository() (owner string, name string) {
+	parts := strings.SplitN(m.Repository, "/", 2)
+
+	if len(parts) != 2 {
+		return "", ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// AnnotationLevel is the severity of an annotation.
+type AnnotationLevel int
+
+// Annotation levels, ordered from the least to the most severe.
+const (
+	LevelDebug AnnotationLevel = iota
+	LevelNotice
+	LevelWarning
+	LevelError
+)
+
+// annotationLevels maps the workflow command names of annotations to their levels.
+var annotationLevels = map[string]AnnotationLevel{
+	"debug":   LevelDebug,
+	"notice":  LevelNotice,
+	"warning": LevelWarning,
+	"error":   LevelError,
+}
+
+// ErrUnknownAnnotationLevel is returned by ParseAnnotationLevel for unrecognised level names.
+var ErrUnknownAnnotationLevel = errors.New("Unknown annotation level")
+
+// String returns the workflow command name of the level, ie. warning.
+func (l AnnotationLevel) String() string {
+	for name, level := range annotationLevels {
+		if level == l {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("AnnotationLevel(%d)", int(l))
+}
+
+// ParseAnnotationLevel returns the level with the given workflow command name, compared
+// case-insensitively. ErrUnknownAnnotationLevel is returned for unrecognised names.
+func ParseAnnotationLevel(s string) (AnnotationLevel, error) {
+	level, ok := annotationLevels[strings.ToLower(s)]
+	if !ok {
+		return 0, ErrUnknownAnnotationLevel
+	}
+
+	return level, nil
 }
 
 // Annotation represents a comment on a specific location in a file.
 type Annotation struct {
 	level   string
 	message string
+	Title   string
 	File    string
 	Line    int
+	EndLine int
 	Col     int
+	EndCol  int
 }
 
 // String serialises an annotation into Action-compatible console entry.
 func (a Annotation) String() string {
 	var params = make([]string, 0)
 
+	if len(a.Title) != 0 {
+		params = append(params, fmt.Sprintf("title=%s", escapeProperty(a.Title)))
+	}
+
 	if len(a.File) != 0 {
-		params = append(params, fmt.Sprintf("file=%s", a.File))
+		params = append(params, fmt.Sprintf("file=%s", escapeProperty(a.File)))
 	}
 
 	// Lines are 1-indexed so a Line of 0 means uninitialised
@@ -73,11 +457,19 @@ func (a Annotation) String() string {
 		params = append(params, fmt.Sprintf("line=%d", a.Line))
 	}
 
+	if a.EndLine != 0 {
+		params = append(params, fmt.Sprintf("endLine=%d", a.EndLine))
+	}
+
 	// Columns are 1-indexed so a Col of 0 means uninitialised
 	if a.Col != 0 {
 		params = append(params, fmt.Sprintf("col=%d", a.Col))
 	}
 
+	if a.EndCol != 0 {
+		params = append(params, fmt.Sprintf("endColumn=%d", a.EndCol))
+	}
+
 	output := fmt.Sprintf("::%s", a.level)
 
 	if len(params) != 0 {
@@ -92,12 +484,199 @@ func (a Annotation) String() string {
 	return fmt.Sprintf("%s::%s", output, a.message)
 }
 
+// Render formats the annotation with a text/template, eg. to produce the annotation syntax of another
+// CI system. The template can refer to {{.Level}}, {{.Title}}, {{.File}}, {{.Line}}, {{.EndLine}},
+// {{.Col}}, {{.EndCol}} and {{.Message}}. The message is not escaped. An empty string is returned if
+// the template is invalid.
+func (a Annotation) Render(tmpl string) string {
+	t, err := template.New("annotation").Parse(tmpl)
+	if err != nil {
+		return ""
+	}
+
+	data := struct {
+		Level, Title, File, Message string
+		Line, EndLine, Col, EndCol  int
+	}{a.level, a.Title, a.File, a.message, a.Line, a.EndLine, a.Col, a.EndCol}
+
+	var output strings.Builder
+
+	if err := t.Execute(&output, data); err != nil {
+		return ""
+	}
+
+	return output.String()
+}
+
+// propertyEscaper escapes the characters which would otherwise end a workflow command property
+// value, mirroring the runner's own escaping.
+// @see https://github.com/actions/toolkit/blob/master/packages/core/src/command.ts
+var propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+func escapeProperty(value string) string {
+	return propertyEscaper.Replace(value)
+}
+
+// normalise returns a copy of the annotation with trailing newlines removed from its message and
+// negative positions, which GitHub would reject, reset. Leading and other trailing whitespace is
+// kept, since it may be significant, ie. for indented output.
+func (a Annotation) normalise() Annotation {
+	a.message = strings.TrimRight(a.message, "\r\n")
+
+	for _, position := range []*int{&a.Line, &a.EndLine, &a.Col, &a.EndCol} {
+		if *position < 0 {
+			*position = 0
+		}
+	}
+
+	return a
+}
+
+// SourceLocation returns the annotation's position in the file:line:col format used by the Go
+// compiler and many linters. Parts which are not set are omitted.
+func (a Annotation) SourceLocation() string {
+	if len(a.File) == 0 {
+		return ""
+	}
+
+	if a.Line == 0 {
+		return a.File
+	}
+
+	if a.Col == 0 {
+		return fmt.Sprintf("%s:%d", a.File, a.Line)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", a.File, a.Line, a.Col)
+}
+
+// Severity returns the numeric rank of the annotation's level: 0 for debug, 1 for notice, 2 for
+// warning and 3 for error. It is useful for sorting and filtering annotations.
+func (a Annotation) Severity() int {
+	return int(annotationLevels[a.level])
+}
+
+// Hash returns a stable, content-based fingerprint of the annotation. It can be used to recognise
+// the same annotation across workflow runs, ie. to suppress known findings.
+func (a Annotation) Hash() string {
+	file := a.File
+
+	if len(file) != 0 {
+		file = filepath.ToSlash(filepath.Clean(file))
+	}
+
+	// Fields are separated by a NUL byte so that adjacent fields cannot produce the same input
+	content := strings.Join([]string{
+		a.level,
+		file,
+		fmt.Sprint(a.Line),
+		fmt.Sprint(a.Col),
+		a.message,
+	}, "\x00")
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// WithMessage returns a copy of the annotation with its message replaced by message.
+func (a Annotation) WithMessage(message string) Annotation {
+	a.message = message
+	return a
+}
+
+// SetPosition returns a copy of the annotation positioned at the given file, line and column.
+func (a Annotation) SetPosition(file string, line, col int) Annotation {
+	a.File = file
+	a.Line = line
+	a.Col = col
+
+	return a
+}
+
+// SetLineRange returns a copy of the annotation spanning the given range of lines and columns in
+// file. The annotation is returned unchanged if endLine is before startLine.
+func (a Annotation) SetLineRange(file string, startLine, endLine, startCol, endCol int) Annotation {
+	if endLine < startLine {
+		return a
+	}
+
+	a.File = file
+	a.Line = startLine
+	a.EndLine = endLine
+	a.Col = startCol
+	a.EndCol = endCol
+
+	return a
+}
+
+// AppendTo appends the annotation to dest and returns it for further chaining.
+func (a Annotation) AppendTo(dest *[]Annotation) Annotation {
+	*dest = append(*dest, a)
+	return a
+}
+
+// Equal reports whether two annotations are identical, including their title and ranges. Use Hash
+// to recognise the same finding across runs instead.
+func (a Annotation) Equal(other Annotation) bool {
+	return a == other
+}
+
+// AnnotationOption configures an optional field of an annotation created with NewAnnotation.
+type AnnotationOption func(*Annotation)
+
+// WithTitle sets the title of the annotation.
+func WithTitle(title string) AnnotationOption {
+	return func(a *Annotation) { a.Title = title }
+}
+
+// WithFile sets the file the annotation refers to.
+func WithFile(file string) AnnotationOption {
+	return func(a *Annotation) { a.File = file }
+}
+
+// WithLine sets the line the annotation starts at.
+func WithLine(line int) AnnotationOption {
+	return func(a *Annotation) { a.Line = line }
+}
+
+// WithEndLine sets the line the annotation ends at.
+func WithEndLine(line int) AnnotationOption {
+	return func(a *Annotation) { a.EndLine = line }
+}
+
+// WithCol sets the column the annotation starts at.
+func WithCol(col int) AnnotationOption {
+	return func(a *Annotation) { a.Col = col }
+}
+
+// WithEndCol sets the column the annotation ends at.
+func WithEndCol(col int) AnnotationOption {
+	return func(a *Annotation) { a.EndCol = col }
+}
+
+// NewAnnotation creates a new annotation of the given level, configured by opts.
+func NewAnnotation(level AnnotationLevel, message string, opts ...AnnotationOption) Annotation {
+	a := Annotation{level: level.String(), message: message}
+
+	for _, opt := range opts {
+		opt(&a)
+	}
+
+	return a
+}
+
 // NewDebug creates a new debug-level annotation.
 // You should set File, Line & Col positions after creation.
 func NewDebug(message string) Annotation {
 	return Annotation{level: "debug", message: message}
 }
 
+// NewNotice creates a new notice-level annotation.
+// You should set File, Line & Col positions after creation.
+func NewNotice(message string) Annotation {
+	return Annotation{level: "notice", message: message}
+}
+
 // NewWarning creates a new warning-level annotation.
 // You should set File, Line & Col positions after creation.
 func NewWarning(message string) Annotation {
@@ -110,31 +689,179 @@ func NewError(message string) Annotation {
 	return Annotation{level: "error", message: message}
 }
 
+// Getenv returns the value of an environment variable and whether it is set at all, which
+// distinguishes variables set to an empty string from missing ones.
+func Getenv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// ErrInvalidEnvName is returned when the name of an environment variable contains characters other
+// than letters, digits and underscores, or starts with a digit.
+var ErrInvalidEnvName = errors.New("Environment variable name is invalid")
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Setenv creates or updates an environment variable for any actions running next in a job.
 // The action that creates or updates the environment variable does not have access to the new
 // value, but all subsequent actions in a job will have access. Environment variables are
-// case-sensitive and you can include punctuation.
-func Setenv(key string, value string) (n int, err error) {
+// case-sensitive and you can include punctuation in the value. The name must consist of letters,
+// digits and underscores and must not start with a digit, otherwise ErrInvalidEnvName is returned.
+// The value is returned as-is so that it can be captured at the call site.
+// The variable is appended to the GITHUB_ENV file when the runner provides one, like Batch does, and
+// the set-env workflow command is only written as a fallback.
+func Setenv(key string, value string) (v string, n int, err error) {
+	if !envNamePattern.MatchString(key) {
+		return value, 0, ErrInvalidEnvName
+	}
+
 	os.Setenv(key, value)
-	return println(fmt.Sprintf("::set-env name=%s::%s", key, value))
+
+	if file := os.Getenv("GITHUB_ENV"); len(file) != 0 {
+		n, err = appendToFile(file, []byte(fileCommand(key, value)))
+		return value, n, err
+	}
+
+	command := fmt.Sprintf("::set-env name=%s::%s", key, value)
+
+	if w := writer(&envOut); w != nil {
+		n, err = fmt.Fprintln(w, command)
+	} else {
+		n, err = println(command)
+	}
+
+	return value, n, err
 }
 
+// MaxOutputSize is the maximum size of an output value, in bytes, accepted by GitHub.
+const MaxOutputSize = 1024 * 1024
+
+// ErrOutputTooLarge is returned when an output value exceeds MaxOutputSize.
+var ErrOutputTooLarge = errors.New("Output value exceeds the maximum size of 1 MiB")
+
+// outputs tracks the names of outputs which have already been set.
+var outputs = struct {
+	sync.Mutex
+	names map[string]struct{}
+}{names: make(map[string]struct{})}
+
 // SetOutput sets an action's output parameter.
 // Output parameters are defined in an action's metadata file. You will receive an error if you
 // attempt to set an output value that was not declared in the action's metadata file.
+// ErrOutputTooLarge is returned for values larger than MaxOutputSize, and an error is returned for
+// names which the runner would ignore, ie. names containing spaces.
+// Setting the same output more than once writes a warning, since the last value silently wins.
+// Empty values are intentionally allowed and always written; the output is then set to an empty
+// string, unlike GetInput which treats empty inputs as missing.
+// The output is appended to the GITHUB_OUTPUT file when the runner provides one, like Batch does.
 func SetOutput(name string, value string) (n int, err error) {
+	if err := validateOutput(name, value); err != nil {
+		return 0, err
+	}
+
+	if !markOutput(name) {
+		if _, err := Warning(fmt.Sprintf("output %s was set more than once", name)); err != nil {
+			return 0, err
+		}
+	}
+
+	return writeOutput(name, value)
+}
+
+// SetOutputStrict works like SetOutput, but returns an error without writing anything if the output
+// has already been set.
+func SetOutputStrict(name string, value string) (n int, err error) {
+	if err := validateOutput(name, value); err != nil {
+		return 0, err
+	}
+
+	if !markOutput(name) {
+		return 0, fmt.Errorf("Output %s was set more than once", name)
+	}
+
+	return writeOutput(name, value)
+}
+
+// writeOutput appends the output to the GITHUB_OUTPUT file if the runner provides one, just like
+// Batch does, and falls back to the set-output workflow command otherwise.
+func writeOutput(name string, value string) (n int, err error) {
+	if file := os.Getenv("GITHUB_OUTPUT"); len(file) != 0 {
+		return appendToFile(file, []byte(fileCommand(name, value)))
+	}
+
 	return println(fmt.Sprintf("::set-output name=%s::%s", name, value))
 }
 
+// SetOutputTime sets an output to t formatted as an RFC 3339 timestamp, which GetInputTime parses.
+func SetOutputTime(name string, t time.Time) (n int, err error) {
+	return SetOutput(name, t.Format(time.RFC3339))
+}
+
+var outputNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_\-]*$`)
+
+// validateOutput checks that an output is acceptable for GitHub.
+func validateOutput(name string, value string) error {
+	if !outputNamePattern.MatchString(name) {
+		return fmt.Errorf("Output name %q is invalid, it must start with a letter or an underscore and contain only letters, digits, underscores and hyphens", name)
+	}
+
+	if len(value) > MaxOutputSize {
+		return ErrOutputTooLarge
+	}
+
+	return nil
+}
+
+// markOutput records that an output has been set and reports whether it is the first time.
+func markOutput(name string) bool {
+	outputs.Lock()
+	defer outputs.Unlock()
+
+	if _, ok := outputs.names[name]; ok {
+		return false
+	}
+
+	outputs.names[name] = struct{}{}
+	return true
+}
+
+// ResetOutputs forgets all outputs set so far, so that setting them again neither writes a warning
+// nor fails SetOutputStrict. This is useful for programs which set outputs in several independent
+// phases, and for tests.
+func ResetOutputs() {
+	outputs.Lock()
+	defer outputs.Unlock()
+
+	outputs.names = make(map[string]struct{})
+}
+
+// ErrRelativePath is returned by PrependPath when the directory is not an absolute path.
+var ErrRelativePath = errors.New("Path must be absolute")
+
 // PrependPath prepends a directory to the system PATH variable for all subsequent actions in the
 // current job. The currently running action cannot access the new path variable.
+// When the runner provides a GITHUB_PATH file the directory is appended to it and any error
+// encountered while writing the file is returned. Relative paths are rejected with ErrRelativePath
+// since they only resolve from the working directory; use UnsafePrependPath if that is intended.
 func PrependPath(path string) (n int, err error) {
+	if !filepath.IsAbs(path) {
+		return 0, ErrRelativePath
+	}
+
+	return UnsafePrependPath(path)
+}
+
+// UnsafePrependPath behaves like PrependPath but accepts relative paths.
+func UnsafePrependPath(path string) (n int, err error) {
 	parts := []string{path, os.Getenv("PATH")}
 
 	if err := os.Setenv("PATH", strings.Join(parts, string(os.PathListSeparator))); err != nil {
 		return 0, err
 	}
 
+	if file := os.Getenv("GITHUB_PATH"); len(file) != 0 {
+		return appendToFile(file, []byte(path+"\n"))
+	}
+
 	return println(fmt.Sprintf("::add-path::%s", path))
 }
 
@@ -144,9 +871,41 @@ func SetSecret(secret string) (n int, err error) {
 }
 
 // GetInput gets the value of an input.  The value is also trimmed.
+// Following the runner's reference implementation, the input is read from the environment variable
+// named INPUT_ followed by the upper-cased name with spaces replaced by underscores. All other
+// characters, such as dots and slashes, are kept as they are. If an input with hyphens in its name
+// is not found, a lookup with the hyphens replaced by underscores is attempted as well since runners
+// are not consistent in this regard.
 func GetInput(name string) (string, error) {
-	key := "INPUT_" + strings.ReplaceAll(strings.ToUpper(name), " ", "_")
-	value := strings.TrimSpace(os.Getenv(key))
+	return getInput(name, strings.TrimSpace)
+}
+
+// GetInputRaw works like GetInput, but returns the value exactly as supplied, without trimming.
+// This is useful for inputs such as keys or multiline secrets which legitimately end with a newline.
+func GetInputRaw(name string) (string, error) {
+	return getInput(name, func(value string) string { return value })
+}
+
+// GetInputWithFallbackNamespace works like GetInput, but if the input is not supplied, the value is
+// read from the environment variable named after the upper-cased namespace followed by an underscore
+// and the input name, eg. MYLIB_MY_INPUT. This allows libraries shared by multiple actions to provide
+// their own defaults.
+func GetInputWithFallbackNamespace(name string, namespace string) (string, error) {
+	value := lookupInput("INPUT_", name, strings.TrimSpace)
+
+	if len(value) == 0 {
+		value = lookupInput(strings.ToUpper(namespace)+"_", name, strings.TrimSpace)
+	}
+
+	if len(value) == 0 {
+		return "", fmt.Errorf("Input %s not supplied or empty string", name)
+	}
+
+	return value, nil
+}
+
+func getInput(name string, clean func(string) string) (string, error) {
+	value := lookupInput("INPUT_", name, clean)
 
 	if len(value) == 0 {
 		return "", fmt.Errorf("Input %s not supplied or empty string", name)
@@ -155,9 +914,166 @@ func GetInput(name string) (string, error) {
 	return value, nil
 }
 
+// lookupInput reads the input from the environment variable named prefix followed by the transformed
+// name, retrying with hyphens replaced by underscores.
+func lookupInput(prefix string, name string, clean func(string) string) string {
+	key := prefix + strings.ReplaceAll(strings.ToUpper(name), " ", "_")
+	value := clean(os.Getenv(key))
+
+	if len(value) == 0 && strings.Contains(key, "-") {
+		value = clean(os.Getenv(strings.ReplaceAll(key, "-", "_")))
+	}
+
+	return value
+}
+
+// GetInputMap returns the values of all inputs supplied to the action, keyed by their lowercased
+// names. The values are trimmed and inputs with empty values are omitted.
+func GetInputMap() map[string]string {
+	inputs := make(map[string]string)
+
+	for _, entry := range os.Environ() {
+		if !strings.HasPrefix(entry, "INPUT_") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(entry, "INPUT_"), "=", 2)
+		value := strings.TrimSpace(parts[1])
+
+		if len(parts[0]) == 0 || len(value) == 0 {
+			continue
+		}
+
+		inputs[strings.ToLower(parts[0])] = value
+	}
+
+	return inputs
+}
+
+// GetInputTime gets the value of an input and parses it as an RFC 3339 timestamp, the format
+// written by SetOutputTime.
+func GetInputTime(name string) (time.Time, error) {
+	value, err := GetInput(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Input %s is not a valid RFC 3339 time: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// ErrInputInvalidEnum is returned by GetInputEnum when an input is not one of the allowed values.
+type ErrInputInvalidEnum struct {
+	Name    string
+	Value   string
+	Allowed []string
+}
+
+func (e ErrInputInvalidEnum) Error() string {
+	return fmt.Sprintf("Input %s has invalid value %s, allowed values are: %s", e.Name, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// GetInputEnum gets the value of an input which must be one of the allowed values. The comparison
+// is case-insensitive and the matching allowed value is returned. ErrInputInvalidEnum is returned
+// if the value is not allowed.
+func GetInputEnum(name string, allowed ...string) (string, error) {
+	return getInputEnum(name, strings.EqualFold, allowed)
+}
+
+// GetInputEnumCaseSensitive works like GetInputEnum, but compares the values case-sensitively.
+func GetInputEnumCaseSensitive(name string, allowed ...string) (string, error) {
+	return getInputEnum(name, func(a, b string) bool { return a == b }, allowed)
+}
+
+func getInputEnum(name string, equal func(string, string) bool, allowed []string) (string, error) {
+	value, err := GetInput(name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, candidate := range allowed {
+		if equal(value, candidate) {
+			return candidate, nil
+		}
+	}
+
+	return "", ErrInputInvalidEnum{Name: name, Value: value, Allowed: allowed}
+}
+
 // Annotate writes an Annotation to the log and to the pull request if file/line/col position is set.
+// Error-level and notice-level annotations are written to the writer configured with
+// SetErrorWriter, if any. Trailing newlines are removed from the message, any other whitespace is
+// written as it is.
 func Annotate(annotation Annotation) (n int, err error) {
-	return println(annotation.String())
+	_, n, err = WriteAnnotation(annotation)
+	return n, err
+}
+
+// AnnotateStringer writes an annotation of the given level with s as its message, positioned at
+// file, line and col. Zero values leave the respective position unset.
+func AnnotateStringer(level AnnotationLevel, s fmt.Stringer, file string, line, col int) (n int, err error) {
+	return Annotate(NewAnnotation(level, s.String()).SetPosition(file, line, col))
+}
+
+// WriteAnnotation works like Annotate, but also returns the annotation as it was written, after
+// normalisation. Trailing newlines are removed from the message and negative positions are reset.
+// Messages longer than MaxAnnotationMessageBytes are truncated and a debug message reports how many
+// bytes were dropped.
+func WriteAnnotation(annotation Annotation) (Annotation, int, error) {
+	annotation, dropped := annotation.normalise().truncate()
+	line := annotation.String()
+
+	var n int
+	var err error
+
+	if w := writer(&errOut); w != nil && (annotation.level == "error" || annotation.level == "notice") {
+		n, err = fmt.Fprintln(w, line)
+	} else {
+		n, err = println(line)
+	}
+
+	if err == nil && dropped != 0 {
+		Debug(fmt.Sprintf("Annotation message exceeded %d bytes, %d bytes were dropped", MaxAnnotationMessageBytes, dropped))
+	}
+
+	return annotation, n, err
+}
+
+// MaxAnnotationMessageBytes is the maximum size of an annotation message, in bytes. GitHub silently
+// truncates longer messages.
+const MaxAnnotationMessageBytes = 64 * 1024
+
+// truncatedSuffix marks messages shortened by truncate.
+const truncatedSuffix = "... [truncated]"
+
+// truncate shortens the message to MaxAnnotationMessageBytes, including a marker, without splitting
+// UTF-8 characters. The number of bytes dropped from the original message is returned as well.
+func (a Annotation) truncate() (Annotation, int) {
+	if len(a.message) <= MaxAnnotationMessageBytes {
+		return a, 0
+	}
+
+	end := MaxAnnotationMessageBytes - len(truncatedSuffix)
+
+	for end > 0 && !utf8.RuneStart(a.message[end]) {
+		end--
+	}
+
+	dropped := len(a.message) - end
+	a.message = a.message[:end] + truncatedSuffix
+
+	return a, dropped
+}
+
+// SetErrorWriter routes error-level and notice-level annotations to w instead of the standard
+// output. This is useful for CI systems which detect failures by reading stderr, ie.
+// SetErrorWriter(os.Stderr). Passing nil restores the default behaviour.
+func SetErrorWriter(w io.Writer) {
+	setWriter(&errOut, w)
 }
 
 // Error Writes an error-level message to the action output.
@@ -170,14 +1086,101 @@ func Warning(message string) (n int, err error) {
 	return Annotate(NewWarning(message))
 }
 
+// Notice writes a notice-level message to the action output.
+func Notice(message string) (n int, err error) {
+	return Annotate(NewNotice(message))
+}
+
 // Debug writes a debug-level message to the action output. Only visible if debugging is enabled.
 func Debug(message string) (n int, err error) {
 	return Annotate(NewDebug(message))
 }
 
+// Errorf works like Error, but formats the message according to a format specifier. The message is
+// formatted directly into a reusable buffer, avoiding the allocation of an intermediate string.
+func Errorf(format string, a ...interface{}) (n int, err error) {
+	return annotatef(LevelError, format, a)
+}
+
+// Warningf works like Warning, but formats the message according to a format specifier.
+func Warningf(format string, a ...interface{}) (n int, err error) {
+	return annotatef(LevelWarning, format, a)
+}
+
+// Debugf works like Debug, but formats the message according to a format specifier.
+func Debugf(format string, a ...interface{}) (n int, err error) {
+	return annotatef(LevelDebug, format, a)
+}
+
+// annotationBuffers holds the buffers used by annotatef.
+var annotationBuffers = sync.Pool{
+	New: func() interface{} { return &bytes.Buffer{} },
+}
+
+// annotatef writes an annotation without a position, formatting and escaping its message in pooled
+// buffers. Messages which have to be truncated are passed on to Annotate instead.
+func annotatef(level AnnotationLevel, format string, a []interface{}) (n int, err error) {
+	message := annotationBuffers.Get().(*bytes.Buffer)
+	line := annotationBuffers.Get().(*bytes.Buffer)
+
+	defer func() {
+		message.Reset()
+		line.Reset()
+		annotationBuffers.Put(message)
+		annotationBuffers.Put(line)
+	}()
+
+	fmt.Fprintf(message, format, a...)
+	trimmed := bytes.TrimRight(message.Bytes(), "\r\n")
+
+	if len(trimmed) > MaxAnnotationMessageBytes {
+		return Annotate(NewAnnotation(level, string(trimmed)))
+	}
+
+	line.WriteString("::")
+	line.WriteString(level.String())
+	line.WriteString("::")
+
+	for _, c := range trimmed {
+		switch c {
+		case '\r':
+			line.WriteString("%0D")
+		case '\n':
+			line.WriteString("%0A")
+		default:
+			line.WriteByte(c)
+		}
+	}
+
+	line.WriteByte('\n')
+
+	w := writer(&out)
+
+	if errWriter := writer(&errOut); errWriter != nil && (level == LevelError || level == LevelNotice) {
+		w = errWriter
+	}
+
+	return w.Write(line.Bytes())
+}
+
+// MaxStackSize is the maximum number of bytes of the stack trace included by DebugWithStack.
+var MaxStackSize = 4096
+
+// DebugWithStack writes a debug-level message followed by the stack trace of the calling goroutine.
+// The stack trace is truncated to MaxStackSize bytes.
+func DebugWithStack(message string) (n int, err error) {
+	stack := debug.Stack()
+
+	if len(stack) > MaxStackSize {
+		stack = stack[:MaxStackSize]
+	}
+
+	return Debug(message + "\n" + string(stack))
+}
+
 // StartGroup starts an output group. Output will be foldable in this group until the next EndGroup.
 func StartGroup(name string) (n int, err error) {
-	return println(fmt.Sprintf("::group name=%s", name))
+	return println(fmt.Sprintf("::group::%s", name))
 }
 
 // EndGroup ends an output group.
@@ -185,13 +1188,104 @@ func EndGroup() (n int, err error) {
 	return println("::endgroup")
 }
 
+// WithGroup runs f inside an output group with the given name. The group is always ended, even if
+// f fails. The first error returned by StartGroup, f or EndGroup is returned.
+func WithGroup(name string, f func() error) (err error) {
+	if _, err := StartGroup(name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if _, endErr := EndGroup(); err == nil {
+			err = endErr
+		}
+	}()
+
+	return f()
+}
+
 // StopCommands stops processing any logging commands.
 // This allows you to log anything without accidentally triggering any command.
+//
+// The endtoken must be an unpredictable value, such as a UUID or a random string, otherwise
+// untrusted output could resume command processing by printing the token itself. An error is
+// returned if endtoken is empty, since command processing could then never be resumed.
 func StopCommands(endtoken string) (n int, err error) {
+	if err := validateToken(endtoken); err != nil {
+		return 0, err
+	}
+
 	return println(fmt.Sprintf("::stop-commands::%s", endtoken))
 }
 
+// StopCommandsRandom works like StopCommands, but generates a random end token and returns it so that
+// it can be passed to ResumeCommands.
+func StopCommandsRandom() (token string, n int, err error) {
+	token, err = newToken()
+	if err != nil {
+		return "", 0, err
+	}
+
+	n, err = StopCommands(token)
+
+	return token, n, err
+}
+
 // ResumeCommands resumes processing logging commands.
+// The endtoken must be the same one which was passed to StopCommands.
 func ResumeCommands(endtoken string) (n int, err error) {
+	if err := validateToken(endtoken); err != nil {
+		return 0, err
+	}
+
 	return println(fmt.Sprintf("::%s::", endtoken))
 }
+
+// SafePrint writes message to the action output with command processing stopped, so that untrusted
+// content such as diffs or test output cannot trigger any command. A random end token which does
+// not occur in the message is used, so the message can never resume command processing itself.
+func SafePrint(message string) (n int, err error) {
+	token, err := newToken()
+
+	for err == nil && strings.Contains(message, token) {
+		token, err = newToken()
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if n, err = StopCommands(token); err != nil {
+		return n, err
+	}
+
+	written, err := println(message)
+	n += written
+
+	if err != nil {
+		return n, err
+	}
+
+	written, err = ResumeCommands(token)
+
+	return n + written, err
+}
+
+func validateToken(endtoken string) error {
+	if len(strings.TrimSpace(endtoken)) == 0 {
+		return fmt.Errorf("End token must not be empty")
+	}
+
+	return nil
+}
+
+// newToken generates a random, hex-encoded token suitable for use with StopCommands.
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -2,9 +2,16 @@ package toolkit
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func Test_GetMetadata(t *testing.T) {
@@ -13,6 +20,350 @@ func Test_GetMetadata(t *testing.T) {
 
 		assert.IsType(t, meta, &Metadata{})
 	})
+
+	t.Run("WorkflowRef", func(t *testing.T) {
+		want := "octocat/hello-world/.github/workflows/ci.yml@refs/heads/main"
+		restoreEnv(t, "GITHUB_WORKFLOW_REF")
+		os.Setenv("GITHUB_WORKFLOW_REF", want)
+
+		assert.Equal(t, want, GetMetadata().WorkflowRef)
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_REF")
+		os.Setenv("GITHUB_REF", "refs/heads/main")
+
+		meta := GetMetadata()
+		os.Setenv("GITHUB_REF", "refs/heads/feature")
+
+		assert.Same(t, meta, meta.Reload())
+		assert.Equal(t, "refs/heads/feature", meta.Ref)
+	})
+
+	t.Run("Token", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_TOKEN")
+		os.Setenv("GITHUB_TOKEN", "secret-token")
+		maskedToken.value = ""
+
+		var meta *Metadata
+		got := capture(func() {
+			meta = GetMetadata()
+			GetMetadata()
+		})
+
+		assert.Equal(t, "secret-token", meta.Token)
+		assert.Equal(t, "::add-mask::secret-token\n", got)
+	})
+
+	t.Run("InternalJobID", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_INTERNAL_JOB_ID")
+		os.Setenv("GITHUB_INTERNAL_JOB_ID", "789")
+
+		assert.Equal(t, "789", GetMetadata().InternalJobID)
+	})
+
+	t.Run("Repository IDs", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_REPOSITORY_ID")
+		restoreEnv(t, "GITHUB_REPOSITORY_OWNER_ID")
+		os.Setenv("GITHUB_REPOSITORY_ID", "123")
+		os.Setenv("GITHUB_REPOSITORY_OWNER_ID", "456")
+
+		meta := GetMetadata()
+
+		assert.Equal(t, "123", meta.RepositoryID)
+		assert.Equal(t, "456", meta.RepositoryOwnerID)
+	})
+}
+
+func Test_PrintMetadata(t *testing.T) {
+	env := map[string]string{"GITHUB_ACTOR": "octocat", "GITHUB_SHA": "2ea0e5d", "GITHUB_TOKEN": "secret-token"}
+
+	for key, value := range env {
+		original, present := os.LookupEnv(key)
+		os.Setenv(key, value)
+
+		if present {
+			defer os.Setenv(key, original)
+		} else {
+			defer os.Unsetenv(key)
+		}
+	}
+
+	// The token is masked when it is first read, which is not what this test is about
+	capture(func() {
+		GetMetadata()
+	})
+
+	var err error
+	got := capture(func() {
+		err = PrintMetadata()
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got, "::group::GitHub Actions Metadata\n"))
+	assert.NotContains(t, got, "Token")
+	assert.Contains(t, got, "::debug::Actor: octocat\n")
+	assert.Contains(t, got, "::debug::Sha: 2ea0e5d\n")
+	assert.True(t, strings.HasSuffix(got, "::endgroup\n"))
+}
+
+func Test_ShortSHA(t *testing.T) {
+	meta := &Metadata{Sha: "2ea0e5d2f5b1c0a4ae5b2dc5c7a8e6d1f3b4c5d6"}
+
+	t.Run("Default length", func(t *testing.T) {
+		assert.Equal(t, "2ea0e5d", meta.ShortSHA(0))
+	})
+
+	t.Run("Custom length", func(t *testing.T) {
+		assert.Equal(t, "2ea0e5d2f5", meta.ShortSHA(10))
+	})
+
+	t.Run("Longer than SHA", func(t *testing.T) {
+		assert.Equal(t, meta.Sha, meta.ShortSHA(100))
+	})
+
+	t.Run("Negative length", func(t *testing.T) {
+		assert.Equal(t, "", meta.ShortSHA(-1))
+	})
+
+	t.Run("Empty SHA", func(t *testing.T) {
+		assert.Equal(t, "", (&Metadata{}).ShortSHA(0))
+	})
+}
+
+func Test_OwnerNameRepoName(t *testing.T) {
+	t.Run("Valid repository", func(t *testing.T) {
+		meta := &Metadata{Repository: "octocat/hello-world"}
+
+		assert.Equal(t, "octocat", meta.OwnerName())
+		assert.Equal(t, "hello-world", meta.RepoName())
+	})
+
+	t.Run("Empty repository", func(t *testing.T) {
+		meta := &Metadata{}
+
+		assert.Equal(t, "", meta.OwnerName())
+		assert.Equal(t, "", meta.RepoName())
+	})
+
+	t.Run("Malformed repository", func(t *testing.T) {
+		meta := &Metadata{Repository: "hello-world"}
+
+		assert.Equal(t, "", meta.OwnerName())
+		assert.Equal(t, "", meta.RepoName())
+	})
+}
+
+func Test_WorkspaceRelPath(t *testing.T) {
+	meta := &Metadata{Workspace: "/home/runner/work/hello-world"}
+
+	t.Run("Inside workspace", func(t *testing.T) {
+		got, err := meta.WorkspaceRelPath("/home/runner/work/hello-world/cmd/main.go")
+
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join("cmd", "main.go"), got)
+	})
+
+	t.Run("Outside workspace", func(t *testing.T) {
+		_, err := meta.WorkspaceRelPath("/home/runner/work/hello-world-fork/main.go")
+
+		assert.EqualError(t, err, "Path /home/runner/work/hello-world-fork/main.go is not inside the workspace /home/runner/work/hello-world")
+	})
+
+	t.Run("Relative path", func(t *testing.T) {
+		_, err := meta.WorkspaceRelPath("cmd/main.go")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Workspace not available", func(t *testing.T) {
+		_, err := (&Metadata{}).WorkspaceRelPath("/home/runner/work/hello-world/main.go")
+
+		assert.EqualError(t, err, "Workspace not available, GITHUB_WORKSPACE is not set")
+	})
+}
+
+func Test_IsReusableWorkflow(t *testing.T) {
+	t.Run("Other repository", func(t *testing.T) {
+		meta := &Metadata{Repository: "octocat/hello-world", ActionRepository: "octocat/shared", ActionRef: "v1"}
+
+		assert.True(t, meta.IsReusableWorkflow())
+	})
+
+	t.Run("Same repository", func(t *testing.T) {
+		meta := &Metadata{Repository: "octocat/hello-world", ActionRepository: "octocat/hello-world", ActionRef: "main"}
+
+		assert.False(t, meta.IsReusableWorkflow())
+	})
+
+	t.Run("Direct step", func(t *testing.T) {
+		meta := &Metadata{Repository: "octocat/hello-world"}
+
+		assert.False(t, meta.IsReusableWorkflow())
+	})
+}
+
+func Test_RefWithoutPrefix(t *testing.T) {
+	t.Run("Branch", func(t *testing.T) {
+		meta := &Metadata{Ref: "refs/heads/feature/login"}
+
+		assert.Equal(t, "feature/login", meta.RefWithoutPrefix())
+	})
+
+	t.Run("Tag", func(t *testing.T) {
+		meta := &Metadata{Ref: "refs/tags/v1.2.3"}
+
+		assert.Equal(t, "v1.2.3", meta.RefWithoutPrefix())
+	})
+
+	t.Run("Other ref", func(t *testing.T) {
+		meta := &Metadata{Ref: "refs/pull/1/merge"}
+
+		assert.Equal(t, "refs/pull/1/merge", meta.RefWithoutPrefix())
+	})
+}
+
+func Test_IsTagIsBranch(t *testing.T) {
+	t.Run("RefType tag", func(t *testing.T) {
+		meta := &Metadata{RefType: "tag", Ref: "refs/tags/v1.0.0"}
+
+		assert.True(t, meta.IsTag())
+		assert.False(t, meta.IsBranch())
+	})
+
+	t.Run("RefType branch", func(t *testing.T) {
+		meta := &Metadata{RefType: "branch", Ref: "refs/heads/main"}
+
+		assert.False(t, meta.IsTag())
+		assert.True(t, meta.IsBranch())
+	})
+
+	t.Run("Ref prefix fallback", func(t *testing.T) {
+		tag := &Metadata{Ref: "refs/tags/v1.0.0"}
+		branch := &Metadata{Ref: "refs/heads/main"}
+
+		assert.True(t, tag.IsTag())
+		assert.False(t, tag.IsBranch())
+		assert.True(t, branch.IsBranch())
+		assert.False(t, branch.IsTag())
+	})
+
+	t.Run("Neither", func(t *testing.T) {
+		meta := &Metadata{Ref: "refs/pull/1/merge"}
+
+		assert.False(t, meta.IsTag())
+		assert.False(t, meta.IsBranch())
+	})
+}
+
+func Test_GetTagName(t *testing.T) {
+	t.Run("Tag", func(t *testing.T) {
+		meta := &Metadata{Ref: "refs/tags/v1.2.3", RefType: "tag"}
+		got, err := meta.GetTagName()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "v1.2.3", got)
+	})
+
+	t.Run("Branch", func(t *testing.T) {
+		meta := &Metadata{Ref: "refs/heads/main", RefType: "branch"}
+		got, err := meta.GetTagName()
+
+		assert.Equal(t, "", got)
+		assert.EqualError(t, err, "Ref refs/heads/main is not a tag")
+	})
+}
+
+func Test_GetBranchName(t *testing.T) {
+	t.Run("Branch", func(t *testing.T) {
+		meta := &Metadata{EventName: "push", Ref: "refs/heads/main", RefType: "branch"}
+		got, err := meta.GetBranchName()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "main", got)
+	})
+
+	t.Run("Pull request", func(t *testing.T) {
+		meta := &Metadata{EventName: "pull_request", Ref: "refs/pull/1/merge", HeadRef: "feature"}
+		got, err := meta.GetBranchName()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "feature", got)
+	})
+
+	t.Run("Tag", func(t *testing.T) {
+		meta := &Metadata{EventName: "push", Ref: "refs/tags/v1.2.3", RefType: "tag"}
+		got, err := meta.GetBranchName()
+
+		assert.Equal(t, "", got)
+		assert.EqualError(t, err, "Ref refs/tags/v1.2.3 is not a branch")
+	})
+}
+
+func Test_PullRequestRefs(t *testing.T) {
+	t.Run("Pull request", func(t *testing.T) {
+		meta := &Metadata{EventName: "pull_request_target", BaseRef: "main", HeadRef: "feature"}
+
+		base, err := meta.PullRequestBaseRef()
+		assert.NoError(t, err)
+		assert.Equal(t, "main", base)
+
+		head, err := meta.PullRequestHeadRef()
+		assert.NoError(t, err)
+		assert.Equal(t, "feature", head)
+	})
+
+	t.Run("Push", func(t *testing.T) {
+		meta := &Metadata{EventName: "push", Ref: "refs/heads/main"}
+
+		_, err := meta.PullRequestBaseRef()
+		assert.EqualError(t, err, "Event push is not a pull request event")
+
+		_, err = meta.PullRequestHeadRef()
+		assert.EqualError(t, err, "Event push is not a pull request event")
+	})
+}
+
+func Test_GetEnvironmentURL(t *testing.T) {
+	t.Run("Available", func(t *testing.T) {
+		meta := &Metadata{Environment: "production", EnvironmentURL: "https://example.com"}
+		got, err := meta.GetEnvironmentURL()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com", got)
+	})
+
+	t.Run("Not available", func(t *testing.T) {
+		got, err := (&Metadata{}).GetEnvironmentURL()
+
+		assert.Equal(t, "", got)
+		assert.EqualError(t, err, "Deployment environment URL not available")
+	})
+}
+
+func Test_WorkflowURL(t *testing.T) {
+	t.Run("WorkflowRef", func(t *testing.T) {
+		meta := &Metadata{
+			ServerURL:   "https://github.example.com",
+			Repository:  "octocat/hello-world",
+			Sha:         "2ea0e5d",
+			WorkflowRef: "octocat/hello-world/.github/workflows/ci.yml@refs/heads/main",
+		}
+
+		want := "https://github.example.com/octocat/hello-world/blob/2ea0e5d/.github/workflows/ci.yml"
+		assert.Equal(t, want, meta.WorkflowURL())
+	})
+
+	t.Run("Workflow fallback", func(t *testing.T) {
+		meta := &Metadata{
+			Repository: "octocat/hello-world",
+			Sha:        "2ea0e5d",
+			Workflow:   ".github/workflows/ci.yml",
+		}
+
+		want := "https://github.com/octocat/hello-world/blob/2ea0e5d/.github/workflows/ci.yml"
+		assert.Equal(t, want, meta.WorkflowURL())
+	})
 }
 
 func Test_NewDebug(t *testing.T) {
@@ -22,6 +373,13 @@ func Test_NewDebug(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func Test_NewNotice(t *testing.T) {
+	want := "::notice::hello world"
+	got := NewNotice("hello world").String()
+
+	assert.Equal(t, want, got)
+}
+
 func Test_NewWarning(t *testing.T) {
 	want := "::warning::hello world"
 	got := NewWarning("hello world").String()
@@ -36,6 +394,26 @@ func Test_NewError(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func Test_NewAnnotation(t *testing.T) {
+	t.Run("Without options", func(t *testing.T) {
+		assert.Equal(t, NewWarning("hello world"), NewAnnotation(LevelWarning, "hello world"))
+	})
+
+	t.Run("With options", func(t *testing.T) {
+		want := "::error title=Greeting,file=/test/file.js,line=5,endLine=6,col=4,endColumn=2::hello world"
+		got := NewAnnotation(LevelError, "hello world",
+			WithTitle("Greeting"),
+			WithFile("/test/file.js"),
+			WithLine(5),
+			WithEndLine(6),
+			WithCol(4),
+			WithEndCol(2),
+		).String()
+
+		assert.Equal(t, want, got)
+	})
+}
+
 func Test_AnnotationFields(t *testing.T) {
 	t.Parallel()
 
@@ -48,6 +426,24 @@ func Test_AnnotationFields(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	t.Run("File with spaces", func(t *testing.T) {
+		want := "::debug file=path with spaces/file.go::hello world"
+		a := NewDebug("hello world")
+		a.File = "path with spaces/file.go"
+		got := a.String()
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("File with special characters", func(t *testing.T) {
+		want := "::debug file=C%3A\\src\\a%2Cb%25.go::hello world"
+		a := NewDebug("hello world")
+		a.File = `C:\src\a,b%.go`
+		got := a.String()
+
+		assert.Equal(t, want, got)
+	})
+
 	t.Run("Line", func(t *testing.T) {
 		want := "::debug line=5::hello world"
 		a := NewDebug("hello world")
@@ -66,6 +462,42 @@ func Test_AnnotationFields(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	t.Run("EndLine", func(t *testing.T) {
+		want := "::debug line=5,endLine=7::hello world"
+		a := NewDebug("hello world")
+		a.Line = 5
+		a.EndLine = 7
+		got := a.String()
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("EndCol", func(t *testing.T) {
+		want := "::debug col=5,endColumn=9::hello world"
+		a := NewDebug("hello world")
+		a.Col = 5
+		a.EndCol = 9
+		got := a.String()
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Title", func(t *testing.T) {
+		want := "::debug title=Greeting::hello world"
+		a := NewDebug("hello world")
+		a.Title = "Greeting"
+		got := a.String()
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Title with special characters", func(t *testing.T) {
+		want := "::error title=a%2C b%3A c::m"
+		got := NewAnnotation(LevelError, "m", WithTitle("a, b: c")).String()
+
+		assert.Equal(t, want, got)
+	})
+
 	t.Run("All", func(t *testing.T) {
 		want := "::debug file=/test/file.js,line=5,col=4::hello world"
 		a := NewDebug("hello world")
@@ -78,39 +510,396 @@ func Test_AnnotationFields(t *testing.T) {
 	})
 }
 
+func Test_AnnotationSourceLocation(t *testing.T) {
+	a := NewError("hello world")
+	assert.Equal(t, "", a.SourceLocation())
+
+	a.File = "main.go"
+	assert.Equal(t, "main.go", a.SourceLocation())
+
+	a.Line = 5
+	assert.Equal(t, "main.go:5", a.SourceLocation())
+
+	a.Col = 4
+	assert.Equal(t, "main.go:5:4", a.SourceLocation())
+}
+
+func Test_AnnotationLevelString(t *testing.T) {
+	assert.Equal(t, "debug", LevelDebug.String())
+	assert.Equal(t, "notice", LevelNotice.String())
+	assert.Equal(t, "warning", LevelWarning.String())
+	assert.Equal(t, "error", LevelError.String())
+	assert.Equal(t, "AnnotationLevel(7)", AnnotationLevel(7).String())
+}
+
+func Test_ParseAnnotationLevel(t *testing.T) {
+	for _, level := range []AnnotationLevel{LevelDebug, LevelNotice, LevelWarning, LevelError} {
+		got, err := ParseAnnotationLevel(strings.ToUpper(level.String()))
+
+		assert.NoError(t, err)
+		assert.Equal(t, level, got)
+	}
+
+	_, err := ParseAnnotationLevel("fatal")
+
+	assert.Equal(t, ErrUnknownAnnotationLevel, err)
+}
+
+func Test_AnnotationSeverity(t *testing.T) {
+	assert.Equal(t, 0, NewDebug("hello world").Severity())
+	assert.Equal(t, 1, NewNotice("hello world").Severity())
+	assert.Equal(t, 2, NewWarning("hello world").Severity())
+	assert.Equal(t, 3, NewError("hello world").Severity())
+}
+
+func Test_AnnotationHash(t *testing.T) {
+	t.Run("Stable", func(t *testing.T) {
+		a := NewError("hello world")
+		a.File = "/path/to/file.js"
+		a.Line = 5
+
+		assert.Len(t, a.Hash(), 64)
+		assert.Equal(t, a.Hash(), a.Hash())
+	})
+
+	t.Run("Normalised file path", func(t *testing.T) {
+		a := NewError("hello world")
+		a.File = "/path/to/../to/file.js"
+		b := NewError("hello world")
+		b.File = "/path/to/file.js"
+
+		assert.Equal(t, a.Hash(), b.Hash())
+	})
+
+	t.Run("Different content", func(t *testing.T) {
+		a := NewError("hello world")
+		b := NewWarning("hello world")
+		c := NewError("hello world")
+		c.Line = 1
+
+		assert.NotEqual(t, a.Hash(), b.Hash())
+		assert.NotEqual(t, a.Hash(), c.Hash())
+	})
+}
+
+func Test_AnnotationEqual(t *testing.T) {
+	a := NewError("hello world").SetPosition("main.go", 5, 1)
+
+	t.Run("Identical", func(t *testing.T) {
+		assert.True(t, a.Equal(NewError("hello world").SetPosition("main.go", 5, 1)))
+	})
+
+	t.Run("Different title", func(t *testing.T) {
+		b := a
+		b.Title = "lint"
+
+		assert.False(t, a.Equal(b))
+	})
+
+	t.Run("Different end line", func(t *testing.T) {
+		b := a
+		b.EndLine = 7
+
+		assert.False(t, a.Equal(b))
+	})
+}
+
+func Test_AnnotationWithMessage(t *testing.T) {
+	a := NewWarning("hello world").SetPosition("main.go", 5, 4)
+	a.Title = "Greeting"
+	got := a.WithMessage("lint: hello world")
+
+	assert.Equal(t, "::warning title=Greeting,file=main.go,line=5,col=4::lint: hello world", got.String())
+	assert.Equal(t, "::warning title=Greeting,file=main.go,line=5,col=4::hello world", a.String())
+}
+
+func Test_AnnotationRender(t *testing.T) {
+	a := NewWarning("unused variable").SetPosition("main.go", 5, 4)
+	a.Title = "lint"
+
+	t.Run("TeamCity", func(t *testing.T) {
+		got := a.Render("##teamcity[inspection file='{{.File}}' line='{{.Line}}' message='{{.Message}}' SEVERITY='{{.Level}}']")
+
+		assert.Equal(t, "##teamcity[inspection file='main.go' line='5' message='unused variable' SEVERITY='warning']", got)
+	})
+
+	t.Run("Azure DevOps", func(t *testing.T) {
+		got := a.Render("##vso[task.logissue type={{.Level}};sourcepath={{.File}};linenumber={{.Line}};columnnumber={{.Col}}]{{.Title}}: {{.Message}}")
+
+		assert.Equal(t, "##vso[task.logissue type=warning;sourcepath=main.go;linenumber=5;columnnumber=4]lint: unused variable", got)
+	})
+
+	t.Run("Invalid template", func(t *testing.T) {
+		assert.Equal(t, "", a.Render("{{.Level"))
+		assert.Equal(t, "", a.Render("{{.Unknown}}"))
+	})
+}
+
+func Test_AnnotationSetPosition(t *testing.T) {
+	want := "::error file=/test/file.js,line=5,col=4::hello world"
+	got := NewError("hello world").SetPosition("/test/file.js", 5, 4).String()
+
+	assert.Equal(t, want, got)
+}
+
+func Test_AnnotationSetLineRange(t *testing.T) {
+	t.Run("Valid range", func(t *testing.T) {
+		want := "::error file=/test/file.js,line=5,endLine=7,col=4,endColumn=2::hello world"
+		got := NewError("hello world").SetLineRange("/test/file.js", 5, 7, 4, 2).String()
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		a := NewError("hello world")
+
+		assert.Equal(t, a, a.SetLineRange("/test/file.js", 7, 5, 1, 1))
+	})
+}
+
+func Test_AnnotationAppendTo(t *testing.T) {
+	annotations := []Annotation{}
+
+	first := NewError("first").AppendTo(&annotations)
+	NewWarning("second").AppendTo(&annotations)
+
+	assert.Equal(t, NewError("first"), first)
+	assert.Equal(t, []Annotation{NewError("first"), NewWarning("second")}, annotations)
+}
+
+func Test_Getenv(t *testing.T) {
+	restoreEnv(t, "TEST_ENV_VAR")
+
+	os.Unsetenv("TEST_ENV_VAR")
+	value, ok := Getenv("TEST_ENV_VAR")
+	assert.Equal(t, "", value)
+	assert.False(t, ok)
+
+	os.Setenv("TEST_ENV_VAR", "")
+	value, ok = Getenv("TEST_ENV_VAR")
+	assert.Equal(t, "", value)
+	assert.True(t, ok)
+
+	os.Setenv("TEST_ENV_VAR", "testvalue")
+	value, ok = Getenv("TEST_ENV_VAR")
+	assert.Equal(t, "testvalue", value)
+	assert.True(t, ok)
+}
+
 func Test_Setenv(t *testing.T) {
 	assert.Empty(t, os.Getenv("TEST_ENV_VAR"))
 	defer os.Unsetenv("TEST_ENV_VAR")
+	restoreEnv(t, "GITHUB_ENV")
+	os.Unsetenv("GITHUB_ENV")
 
+	var value string
 	want := "::set-env name=TEST_ENV_VAR::testvalue\n"
 	got := capture(func() {
-		Setenv("TEST_ENV_VAR", "testvalue")
+		value, _, _ = Setenv("TEST_ENV_VAR", "testvalue")
 	})
 
 	assert.Equal(t, want, got)
+	assert.Equal(t, "testvalue", value)
 	assert.Equal(t, "testvalue", os.Getenv("TEST_ENV_VAR"))
 }
 
+func Test_Setenv_InvalidName(t *testing.T) {
+	for _, name := range []string{"MY VAR", "MY=VAR", "1VAR", "", "VAR\x00"} {
+		t.Run(name, func(t *testing.T) {
+			got := capture(func() {
+				_, _, err := Setenv(name, "testvalue")
+				assert.Equal(t, ErrInvalidEnvName, err)
+			})
+
+			assert.Empty(t, got)
+			assert.Empty(t, os.Getenv(name))
+		})
+	}
+}
+
+func Test_SetEnvWriter(t *testing.T) {
+	restoreEnv(t, "GITHUB_ENV")
+	os.Unsetenv("GITHUB_ENV")
+	defer os.Unsetenv("TEST_ENV_VAR")
+
+	buffer := &bytes.Buffer{}
+	SetEnvWriter(buffer)
+	defer SetEnvWriter(nil)
+
+	got := capture(func() {
+		Setenv("TEST_ENV_VAR", "testvalue")
+	})
+
+	assert.Empty(t, got)
+	assert.Equal(t, "::set-env name=TEST_ENV_VAR::testvalue\n", buffer.String())
+}
+
+func Test_SetOutputWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	SetOutputWriter(buffer)
+	Debug("hello world")
+	SetOutputWriter(nil)
+
+	assert.Equal(t, "::debug::hello world\n", buffer.String())
+	assert.Equal(t, os.Stdout, out)
+}
+
 func Test_SetOutput(t *testing.T) {
-	want := "::set-output name=testkey::testvalue\n"
+	restoreEnv(t, "GITHUB_OUTPUT")
+	os.Unsetenv("GITHUB_OUTPUT")
+
+	t.Run("Single output", func(t *testing.T) {
+		defer ResetOutputs()
+
+		want := "::set-output name=testkey::testvalue\n"
+		got := capture(func() {
+			SetOutput("testkey", "testvalue")
+		})
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Empty value", func(t *testing.T) {
+		defer ResetOutputs()
+
+		var err error
+		want := "::set-output name=testkey::\n"
+		got := capture(func() {
+			_, err = SetOutput("testkey", "")
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Value too large", func(t *testing.T) {
+		defer ResetOutputs()
+
+		var err error
+		got := capture(func() {
+			_, err = SetOutput("testkey", strings.Repeat("a", MaxOutputSize+1))
+		})
+
+		assert.Equal(t, ErrOutputTooLarge, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Duplicate output", func(t *testing.T) {
+		defer ResetOutputs()
+
+		want := "::set-output name=testkey::first\n" +
+			"::warning::output testkey was set more than once\n" +
+			"::set-output name=testkey::second\n"
+		got := capture(func() {
+			SetOutput("testkey", "first")
+			SetOutput("testkey", "second")
+		})
+
+		assert.Equal(t, want, got)
+	})
+}
+
+func Test_SetOutput_InvalidName(t *testing.T) {
+	for _, name := range []string{"my output", "1st", "", "out.put"} {
+		t.Run(name, func(t *testing.T) {
+			defer ResetOutputs()
+
+			got := capture(func() {
+				_, err := SetOutput(name, "testvalue")
+				assert.EqualError(t, err, fmt.Sprintf("Output name %q is invalid, it must start with a letter or an underscore and contain only letters, digits, underscores and hyphens", name))
+			})
+
+			assert.Empty(t, got)
+		})
+	}
+}
+
+func Test_SetOutputStrict(t *testing.T) {
+	restoreEnv(t, "GITHUB_OUTPUT")
+	os.Unsetenv("GITHUB_OUTPUT")
+
+	defer ResetOutputs()
+
+	var err error
+	want := "::set-output name=testkey::first\n"
 	got := capture(func() {
-		SetOutput("testkey", "testvalue")
+		SetOutputStrict("testkey", "first")
+		_, err = SetOutputStrict("testkey", "second")
 	})
 
 	assert.Equal(t, want, got)
+	assert.EqualError(t, err, "Output testkey was set more than once")
+}
+
+func Test_ResetOutputs(t *testing.T) {
+	restoreEnv(t, "GITHUB_OUTPUT")
+	os.Unsetenv("GITHUB_OUTPUT")
+
+	defer ResetOutputs()
+
+	var err error
+	want := "::set-output name=testkey::first\n::set-output name=testkey::second\n"
+	got := capture(func() {
+		SetOutputStrict("testkey", "first")
+		ResetOutputs()
+		_, err = SetOutputStrict("testkey", "second")
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
 }
 
 func Test_PrependPath(t *testing.T) {
 	path := os.Getenv("PATH")
 	defer os.Setenv("PATH", path)
 
-	want := "::add-path::/usr/dummy/bin\n"
+	t.Run("Workflow command", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_PATH")
+		os.Unsetenv("GITHUB_PATH")
+
+		want := "::add-path::/usr/dummy/bin\n"
+		got := capture(func() {
+			PrependPath("/usr/dummy/bin")
+		})
+
+		assert.Contains(t, os.Getenv("PATH"), "/usr/dummy/bin")
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("GITHUB_PATH not writable", func(t *testing.T) {
+		restoreEnv(t, "GITHUB_PATH")
+		os.Setenv("GITHUB_PATH", "/nonexistent/directory/path")
+
+		_, err := PrependPath("/usr/dummy/bin")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Relative path", func(t *testing.T) {
+		before := os.Getenv("PATH")
+		got := capture(func() {
+			_, err := PrependPath("./tools")
+			assert.Equal(t, ErrRelativePath, err)
+		})
+
+		assert.Empty(t, got)
+		assert.Equal(t, before, os.Getenv("PATH"))
+	})
+}
+
+func Test_UnsafePrependPath(t *testing.T) {
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	restoreEnv(t, "GITHUB_PATH")
+	os.Unsetenv("GITHUB_PATH")
+
+	want := "::add-path::./tools\n"
 	got := capture(func() {
-		PrependPath("/usr/dummy/bin")
+		UnsafePrependPath("./tools")
 	})
 
-	assert.Contains(t, os.Getenv("PATH"), "/usr/dummy/bin")
 	assert.Equal(t, want, got)
+	assert.True(t, strings.HasPrefix(os.Getenv("PATH"), "./tools"))
 }
 
 func Test_SetSecret(t *testing.T) {
@@ -163,6 +952,36 @@ func Test_GetInput(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	t.Run("Hyphens", func(t *testing.T) {
+		os.Setenv("INPUT_TEST-INPUT", "testval")
+		defer os.Unsetenv("INPUT_TEST-INPUT")
+
+		want := "testval"
+		got, _ := GetInput("test-input")
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Hyphens with underscore fallback", func(t *testing.T) {
+		os.Setenv("INPUT_TEST_INPUT", "testval")
+		defer os.Unsetenv("INPUT_TEST_INPUT")
+
+		want := "testval"
+		got, _ := GetInput("test-input")
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Dots and slashes", func(t *testing.T) {
+		os.Setenv("INPUT_CONFIG.PATH/NAME", "testval")
+		defer os.Unsetenv("INPUT_CONFIG.PATH/NAME")
+
+		want := "testval"
+		got, _ := GetInput("config.path/name")
+
+		assert.Equal(t, want, got)
+	})
+
 	t.Run("Non-existent input", func(t *testing.T) {
 		want := ""
 		got, err := GetInput("TESTINPUT")
@@ -182,6 +1001,207 @@ func Test_GetInput(t *testing.T) {
 	})
 }
 
+func Test_GetInputRaw(t *testing.T) {
+	t.Run("Untrimmed", func(t *testing.T) {
+		os.Setenv("INPUT_KEY", "  -----BEGIN KEY-----\nabc\n-----END KEY-----\n")
+		defer os.Unsetenv("INPUT_KEY")
+
+		got, err := GetInputRaw("key")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "  -----BEGIN KEY-----\nabc\n-----END KEY-----\n", got)
+	})
+
+	t.Run("Hyphenated name", func(t *testing.T) {
+		os.Setenv("INPUT_GPG_KEY", "abc\n")
+		defer os.Unsetenv("INPUT_GPG_KEY")
+
+		got, err := GetInputRaw("gpg-key")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc\n", got)
+	})
+
+	t.Run("Missing input", func(t *testing.T) {
+		_, err := GetInputRaw("missing")
+
+		assert.EqualError(t, err, "Input missing not supplied or empty string")
+	})
+}
+
+func Test_GetInputWithFallbackNamespace(t *testing.T) {
+	os.Setenv("MYLIB_LOG_LEVEL", "debug")
+	defer os.Unsetenv("MYLIB_LOG_LEVEL")
+
+	t.Run("Input", func(t *testing.T) {
+		os.Setenv("INPUT_LOG_LEVEL", "warning")
+		defer os.Unsetenv("INPUT_LOG_LEVEL")
+
+		got, err := GetInputWithFallbackNamespace("log-level", "mylib")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "warning", got)
+	})
+
+	t.Run("Namespace", func(t *testing.T) {
+		got, err := GetInputWithFallbackNamespace("log-level", "mylib")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "debug", got)
+	})
+
+	t.Run("Missing input", func(t *testing.T) {
+		_, err := GetInputWithFallbackNamespace("missing", "mylib")
+
+		assert.EqualError(t, err, "Input missing not supplied or empty string")
+	})
+}
+
+func Test_GetInputMap(t *testing.T) {
+	os.Setenv("INPUT_FIRST_INPUT", " first ")
+	os.Setenv("INPUT_SECOND", "second")
+	os.Setenv("INPUT_EMPTY", "  ")
+	defer os.Unsetenv("INPUT_FIRST_INPUT")
+	defer os.Unsetenv("INPUT_SECOND")
+	defer os.Unsetenv("INPUT_EMPTY")
+
+	got := GetInputMap()
+
+	assert.Equal(t, "first", got["first_input"])
+	assert.Equal(t, "second", got["second"])
+	assert.NotContains(t, got, "empty")
+}
+
+func Test_SetOutputTime(t *testing.T) {
+	restoreEnv(t, "GITHUB_OUTPUT")
+	os.Unsetenv("GITHUB_OUTPUT")
+
+	defer ResetOutputs()
+
+	value := time.Date(2020, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+	got := capture(func() {
+		SetOutputTime("built-at", value)
+	})
+
+	assert.Equal(t, "::set-output name=built-at::2020-03-01T12:30:00+01:00\n", got)
+}
+
+func Test_GetInputTime(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		os.Setenv("INPUT_SINCE", "2020-03-01T12:30:00+01:00")
+		defer os.Unsetenv("INPUT_SINCE")
+
+		got, err := GetInputTime("since")
+
+		assert.NoError(t, err)
+		assert.True(t, time.Date(2020, 3, 1, 11, 30, 0, 0, time.UTC).Equal(got))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		os.Setenv("INPUT_SINCE", "yesterday")
+		defer os.Unsetenv("INPUT_SINCE")
+
+		_, err := GetInputTime("since")
+
+		assert.True(t, strings.HasPrefix(err.Error(), "Input since is not a valid RFC 3339 time: "))
+	})
+
+	t.Run("Missing input", func(t *testing.T) {
+		_, err := GetInputTime("missing")
+
+		assert.EqualError(t, err, "Input missing not supplied or empty string")
+	})
+}
+
+func Test_GetInputEnum(t *testing.T) {
+	os.Setenv("INPUT_LEVEL", "Warn")
+	defer os.Unsetenv("INPUT_LEVEL")
+
+	t.Run("Case-insensitive", func(t *testing.T) {
+		got, err := GetInputEnum("level", "debug", "info", "warn", "error")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "warn", got)
+	})
+
+	t.Run("Case-sensitive", func(t *testing.T) {
+		_, err := GetInputEnumCaseSensitive("level", "debug", "info", "warn", "error")
+
+		assert.Equal(t, ErrInputInvalidEnum{Name: "level", Value: "Warn", Allowed: []string{"debug", "info", "warn", "error"}}, err)
+		assert.EqualError(t, err, "Input level has invalid value Warn, allowed values are: debug, info, warn, error")
+	})
+
+	t.Run("Missing input", func(t *testing.T) {
+		_, err := GetInputEnum("missing", "debug")
+
+		assert.EqualError(t, err, "Input missing not supplied or empty string")
+	})
+}
+
+func Test_WriteAnnotation(t *testing.T) {
+	var written Annotation
+	var n int
+	var err error
+	a := NewError("  hello world\n")
+	a.File = "main.go"
+	a.Line = -1
+
+	got := capture(func() {
+		written, n, err = WriteAnnotation(a)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "::error file=main.go::  hello world\n", got)
+	assert.Equal(t, len(got), n)
+	assert.Equal(t, "main.go", written.File)
+	assert.Equal(t, 0, written.Line)
+	assert.Equal(t, "::error file=main.go::  hello world", written.String())
+}
+
+func Test_WriteAnnotation_Truncated(t *testing.T) {
+	t.Run("ASCII", func(t *testing.T) {
+		var written Annotation
+		got := capture(func() {
+			written, _, _ = WriteAnnotation(NewWarning(strings.Repeat("a", MaxAnnotationMessageBytes+10)))
+		})
+
+		lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+		assert.Len(t, lines, 2)
+		assert.Len(t, written.message, MaxAnnotationMessageBytes)
+		assert.True(t, strings.HasSuffix(lines[0], "a... [truncated]"))
+		assert.Equal(t, fmt.Sprintf("::debug::Annotation message exceeded %d bytes, %d bytes were dropped", MaxAnnotationMessageBytes, 10+len("... [truncated]")), lines[1])
+	})
+
+	t.Run("UTF-8 boundary", func(t *testing.T) {
+		var written Annotation
+		capture(func() {
+			written, _, _ = WriteAnnotation(NewWarning("a" + strings.Repeat("é", MaxAnnotationMessageBytes/2)))
+		})
+
+		assert.True(t, utf8.ValidString(written.message))
+		assert.True(t, len(written.message) <= MaxAnnotationMessageBytes)
+		assert.True(t, strings.HasSuffix(written.message, "é... [truncated]"))
+	})
+
+	t.Run("Within limit", func(t *testing.T) {
+		message := strings.Repeat("a", MaxAnnotationMessageBytes)
+		got := capture(func() {
+			WriteAnnotation(NewWarning(message))
+		})
+
+		assert.Equal(t, "::warning::"+message+"\n", got)
+	})
+}
+
+func Test_AnnotateStringer(t *testing.T) {
+	want := "::warning file=main.go,line=5::1.5s\n"
+	got := capture(func() {
+		AnnotateStringer(LevelWarning, 1500*time.Millisecond, "main.go", 5, 0)
+	})
+
+	assert.Equal(t, want, got)
+}
+
 func Test_Error(t *testing.T) {
 	want := "::error::hello world\n"
 	got := capture(func() {
@@ -191,6 +1211,21 @@ func Test_Error(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func Test_SetErrorWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	SetErrorWriter(buffer)
+	defer SetErrorWriter(nil)
+
+	got := capture(func() {
+		Error("hello error")
+		Notice("hello notice")
+		Warning("hello warning")
+	})
+
+	assert.Equal(t, "::error::hello error\n::notice::hello notice\n", buffer.String())
+	assert.Equal(t, "::warning::hello warning\n", got)
+}
+
 func Test_Warning(t *testing.T) {
 	want := "::warning::hello world\n"
 	got := capture(func() {
@@ -200,6 +1235,15 @@ func Test_Warning(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func Test_Notice(t *testing.T) {
+	want := "::notice::hello world\n"
+	got := capture(func() {
+		Notice("hello world")
+	})
+
+	assert.Equal(t, want, got)
+}
+
 func Test_Debug(t *testing.T) {
 	want := "::debug::hello world\n"
 	got := capture(func() {
@@ -208,8 +1252,92 @@ func Test_Debug(t *testing.T) {
 
 	assert.Equal(t, want, got)
 }
+
+func Test_Formatted(t *testing.T) {
+	t.Run("Debugf", func(t *testing.T) {
+		got := capture(func() {
+			Debugf("processed %d files in %s", 3, "src")
+		})
+
+		assert.Equal(t, "::debug::processed 3 files in src\n", got)
+	})
+
+	t.Run("Warningf", func(t *testing.T) {
+		got := capture(func() {
+			Warningf("line one\r\n%s\n", "line two")
+		})
+
+		assert.Equal(t, "::warning::line one%0D%0Aline two\n", got)
+	})
+
+	t.Run("Errorf", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		SetErrorWriter(buffer)
+		defer SetErrorWriter(nil)
+
+		got := capture(func() {
+			Errorf("failed: %v", errors.New("boom"))
+		})
+
+		assert.Empty(t, got)
+		assert.Equal(t, "::error::failed: boom\n", buffer.String())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		got := capture(func() {
+			Debugf("%s", strings.Repeat("a", MaxAnnotationMessageBytes+1))
+		})
+
+		assert.Contains(t, got, "a... [truncated]\n::debug::Annotation message exceeded")
+	})
+}
+
+func Benchmark_Debugf(b *testing.B) {
+	SetOutputWriter(ioutil.Discard)
+	defer SetOutputWriter(nil)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		Debugf("processed %d files in %s", i, "src")
+	}
+}
+
+func Benchmark_DebugSprintf(b *testing.B) {
+	SetOutputWriter(ioutil.Discard)
+	defer SetOutputWriter(nil)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		Debug(fmt.Sprintf("processed %d files in %s", i, "src"))
+	}
+}
+
+func Test_DebugWithStack(t *testing.T) {
+	t.Run("Stack trace", func(t *testing.T) {
+		got := capture(func() {
+			DebugWithStack("hello world")
+		})
+
+		assert.True(t, strings.HasPrefix(got, "::debug::hello world%0Agoroutine "))
+		assert.Contains(t, got, "Test_DebugWithStack")
+		assert.Equal(t, 1, strings.Count(got, "\n"))
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		original := MaxStackSize
+		MaxStackSize = 10
+		defer func() { MaxStackSize = original }()
+
+		got := capture(func() {
+			DebugWithStack("hello world")
+		})
+
+		assert.Equal(t, "::debug::hello world%0Agoroutine \n", got)
+	})
+}
+
 func Test_StartGroup(t *testing.T) {
-	want := "::group name=hello world\n"
+	want := "::group::hello world\n"
 	got := capture(func() {
 		StartGroup("hello world")
 	})
@@ -226,6 +1354,35 @@ func Test_EndGroup(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func Test_WithGroup(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var err error
+		want := "::group::hello world\ninside\n::endgroup\n"
+		got := capture(func() {
+			err = WithGroup("hello world", func() error {
+				println("inside")
+				return nil
+			})
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		var err error
+		want := "::group::hello world\n::endgroup\n"
+		got := capture(func() {
+			err = WithGroup("hello world", func() error {
+				return errors.New("failed")
+			})
+		})
+
+		assert.EqualError(t, err, "failed")
+		assert.Equal(t, want, got)
+	})
+}
+
 func Test_StopCommands(t *testing.T) {
 	want := "::stop-commands::hello world\n"
 	got := capture(func() {
@@ -235,6 +1392,72 @@ func Test_StopCommands(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func Test_StopCommandsRoundTrip(t *testing.T) {
+	token, err := newToken()
+
+	assert.NoError(t, err)
+	assert.Len(t, token, 32)
+
+	stop := capture(func() {
+		StopCommands(token)
+	})
+	resume := capture(func() {
+		ResumeCommands(token)
+	})
+
+	got := strings.TrimSuffix(strings.TrimPrefix(stop, "::stop-commands::"), "\n")
+	assert.Equal(t, token, got)
+	assert.Equal(t, "::"+got+"::\n", resume)
+}
+
+func Test_StopCommandsRandom(t *testing.T) {
+	var token string
+	var err error
+
+	stop := capture(func() {
+		token, _, err = StopCommandsRandom()
+	})
+	resume := capture(func() {
+		ResumeCommands(token)
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, token, 32)
+	assert.Equal(t, "::stop-commands::"+token+"\n", stop)
+	assert.Equal(t, "::"+token+"::\n", resume)
+}
+
+func Test_StopCommandsEmptyToken(t *testing.T) {
+	for _, token := range []string{"", "  \n"} {
+		var stopErr, resumeErr error
+		got := capture(func() {
+			_, stopErr = StopCommands(token)
+			_, resumeErr = ResumeCommands(token)
+		})
+
+		assert.EqualError(t, stopErr, "End token must not be empty")
+		assert.EqualError(t, resumeErr, "End token must not be empty")
+		assert.Empty(t, got)
+	}
+}
+
+func Test_SafePrint(t *testing.T) {
+	var n int
+	var err error
+	got := capture(func() {
+		n, err = SafePrint("::error::injected")
+	})
+
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(got), n)
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "::stop-commands::"))
+	assert.Equal(t, "::error::injected", lines[1])
+	assert.Equal(t, "::"+strings.TrimPrefix(lines[0], "::stop-commands::")+"::", lines[2])
+}
+
 func Test_ResumeCommands(t *testing.T) {
 	want := "::hello world::\n"
 	got := capture(func() {
@@ -244,6 +1467,20 @@ func Test_ResumeCommands(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+// restoreEnv registers a cleanup function which restores the current value of an environment
+// variable, or unsets it if it is not set.
+func restoreEnv(t *testing.T, key string) {
+	original, present := os.LookupEnv(key)
+
+	t.Cleanup(func() {
+		if present {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
 // capture stubs the package's output to stdout and instead stores the output in a buffer.
 func capture(f func()) string {
 	original := out
@@ -0,0 +1,66 @@
+package toolkit
+
+// AnnotationTransform rewrites an annotation, eg. to change its level before it is emitted.
+type AnnotationTransform func(Annotation) Annotation
+
+// PromoteWarningsToErrors returns a transform which turns warnings into errors and leaves all other
+// levels unchanged, similar to -Werror in C compilers.
+func PromoteWarningsToErrors() AnnotationTransform {
+	return func(a Annotation) Annotation {
+		if a.level == LevelWarning.String() {
+			a.level = LevelError.String()
+		}
+
+		return a
+	}
+}
+
+// ApplyTransforms returns a new slice with every transform applied, in order, to each annotation.
+func ApplyTransforms(annotations []Annotation, transforms ...AnnotationTransform) []Annotation {
+	result := make([]Annotation, 0, len(annotations))
+
+	for _, annotation := range annotations {
+		for _, transform := range transforms {
+			annotation = transform(annotation)
+		}
+
+		result = append(result, annotation)
+	}
+
+	return result
+}
+
+// Filter returns a new slice with the annotations for which predicate returns true.
+func Filter(annotations []Annotation, predicate func(Annotation) bool) []Annotation {
+	result := make([]Annotation, 0)
+
+	for _, annotation := range annotations {
+		if predicate(annotation) {
+			result = append(result, annotation)
+		}
+	}
+
+	return result
+}
+
+// ByLevel returns a predicate for Filter which matches annotations of the given level.
+func ByLevel(level AnnotationLevel) func(Annotation) bool {
+	return func(a Annotation) bool {
+		return a.Severity() == int(level)
+	}
+}
+
+// ByFile returns a predicate for Filter which matches annotations positioned in file.
+func ByFile(file string) func(Annotation) bool {
+	return func(a Annotation) bool {
+		return a.File == file
+	}
+}
+
+// ByLineRange returns a predicate for Filter which matches annotations starting between the start
+// and end lines, inclusive. Annotations without a line never match.
+func ByLineRange(start, end int) func(Annotation) bool {
+	return func(a Annotation) bool {
+		return a.Line != 0 && a.Line >= start && a.Line <= end
+	}
+}
@@ -0,0 +1,60 @@
+package toolkit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_PromoteWarningsToErrors(t *testing.T) {
+	promote := PromoteWarningsToErrors()
+
+	assert.Equal(t, NewError("careful"), promote(NewWarning("careful")))
+	assert.Equal(t, NewNotice("hello"), promote(NewNotice("hello")))
+	assert.Equal(t, NewError("broken"), promote(NewError("broken")))
+}
+
+func Test_ApplyTransforms(t *testing.T) {
+	annotations := []Annotation{NewWarning("first"), NewDebug("second")}
+	prefix := func(a Annotation) Annotation {
+		return a.WithMessage("lint: " + a.message)
+	}
+
+	want := []Annotation{NewError("lint: first"), NewDebug("lint: second")}
+	got := ApplyTransforms(annotations, PromoteWarningsToErrors(), prefix)
+
+	assert.Equal(t, want, got)
+	assert.Equal(t, NewWarning("first"), annotations[0])
+}
+
+func Test_Filter(t *testing.T) {
+	annotations := []Annotation{
+		NewError("first").SetPosition("main.go", 3, 1),
+		NewWarning("second").SetPosition("main.go", 10, 1),
+		NewError("third").SetPosition("util.go", 5, 1),
+		NewError("fourth"),
+	}
+
+	t.Run("ByLevel", func(t *testing.T) {
+		got := Filter(annotations, ByLevel(LevelWarning))
+
+		assert.Equal(t, []Annotation{annotations[1]}, got)
+	})
+
+	t.Run("ByFile", func(t *testing.T) {
+		got := Filter(annotations, ByFile("main.go"))
+
+		assert.Equal(t, []Annotation{annotations[0], annotations[1]}, got)
+	})
+
+	t.Run("ByLineRange", func(t *testing.T) {
+		got := Filter(annotations, ByLineRange(1, 5))
+
+		assert.Equal(t, []Annotation{annotations[0], annotations[2]}, got)
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		got := Filter(annotations, ByFile("missing.go"))
+
+		assert.Empty(t, got)
+	})
+}
@@ -0,0 +1,56 @@
+package toolkit
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath is the import path of the module the toolkit is released as.
+const modulePath = "github.com/robertrossmann/actions"
+
+// develVersion is reported as Version when the toolkit's version is not recorded in the build info
+// of the running binary, ie. when it is built from a working copy of this repository.
+const develVersion = "devel"
+
+// Version is the version of the toolkit module the running binary was built with, as recorded in its
+// build info, without the leading v. It is "devel" if the version is not known.
+var Version = moduleVersion(debug.ReadBuildInfo())
+
+// moduleVersion returns the version of the toolkit module recorded in the build info, taking
+// replace directives into account.
+func moduleVersion(info *debug.BuildInfo, ok bool) string {
+	if !ok {
+		return develVersion
+	}
+
+	var module *debug.Module
+
+	if info.Main.Path == modulePath {
+		module = &info.Main
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			module = dep
+		}
+	}
+
+	if module == nil {
+		return develVersion
+	}
+
+	if module.Replace != nil {
+		module = module.Replace
+	}
+
+	if len(module.Version) == 0 || module.Version == "(devel)" {
+		return develVersion
+	}
+
+	return strings.TrimPrefix(module.Version, "v")
+}
+
+// UserAgent returns the value actions should send in the User-Agent header of outbound HTTP requests.
+func UserAgent() string {
+	return "actions-toolkit-go/" + Version
+}
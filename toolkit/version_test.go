@@ -0,0 +1,42 @@
+package toolkit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"runtime/debug"
+	"testing"
+)
+
+func Test_UserAgent(t *testing.T) {
+	assert.Equal(t, "actions-toolkit-go/"+Version, UserAgent())
+}
+
+func Test_moduleVersion(t *testing.T) {
+	cases := []struct {
+		name string
+		info *debug.BuildInfo
+		ok   bool
+		want string
+	}{
+		{"No build info", nil, false, "devel"},
+		{"Dependency", &debug.BuildInfo{
+			Main: debug.Module{Path: "github.com/octocat/action"},
+			Deps: []*debug.Module{{Path: modulePath, Version: "v1.2.3"}},
+		}, true, "1.2.3"},
+		{"Replaced dependency", &debug.BuildInfo{
+			Main: debug.Module{Path: "github.com/octocat/action"},
+			Deps: []*debug.Module{{Path: modulePath, Version: "v1.2.3", Replace: &debug.Module{Path: "github.com/octocat/actions", Version: "v1.2.4"}}},
+		}, true, "1.2.4"},
+		{"Replaced by a directory", &debug.BuildInfo{
+			Main: debug.Module{Path: "github.com/octocat/action"},
+			Deps: []*debug.Module{{Path: modulePath, Version: "v1.2.3", Replace: &debug.Module{Path: "../actions"}}},
+		}, true, "devel"},
+		{"Main module", &debug.BuildInfo{Main: debug.Module{Path: modulePath, Version: "(devel)"}}, true, "devel"},
+		{"Not a dependency", &debug.BuildInfo{Main: debug.Module{Path: "github.com/octocat/action"}}, true, "devel"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, moduleVersion(c.info, c.ok))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Package webhook implements verification of GitHub webhook payloads.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidSignature is returned when a payload's signature does not match its contents.
+var ErrInvalidSignature = errors.New("Webhook signature does not match payload")
+
+// Verify checks that signature, the value of the X-Hub-Signature-256 header, is a valid HMAC-SHA256
+// signature of body created with secret. The signatures are compared in constant time.
+func Verify(secret string, signature string, body []byte) error {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return errors.New("Webhook signature must start with sha256=")
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
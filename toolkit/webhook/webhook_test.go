@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Example taken from GitHub's documentation on validating webhook deliveries
+const (
+	secret    = "It's a Secret to Everybody"
+	payload   = "Hello, World!"
+	signature = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+)
+
+func Test_Verify(t *testing.T) {
+	t.Run("Valid signature", func(t *testing.T) {
+		assert.NoError(t, Verify(secret, signature, []byte(payload)))
+	})
+
+	t.Run("Tampered payload", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidSignature, Verify(secret, signature, []byte("Goodbye, World!")))
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidSignature, Verify("wrong", signature, []byte(payload)))
+	})
+
+	t.Run("Malformed signature", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidSignature, Verify(secret, "sha256=zz", []byte(payload)))
+	})
+
+	t.Run("Missing prefix", func(t *testing.T) {
+		assert.EqualError(t, Verify(secret, "757107ea", []byte(payload)), "Webhook signature must start with sha256=")
+	})
+}